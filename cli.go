@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukasjoc/nemo/internal/assets"
+	"github.com/lukasjoc/nemo/internal/config"
+	"github.com/lukasjoc/nemo/internal/idle"
+	"github.com/lukasjoc/nemo/internal/layer"
+	"github.com/lukasjoc/nemo/internal/record"
+	"github.com/lukasjoc/nemo/internal/renderer"
+)
+
+// The version of nemo, which can be set when building with
+// `-ldflags "-X main.version=..."`.
+var version = "1.1"
+
+const usage = `Usage: nemo [command] [flags]
+
+Commands:
+  run             run the aquarium, this is the default
+  record <file>   run the aquarium and record it to a file
+  replay <file>   replay a recording
+  assets [group]  list all the assets or the ones of a group
+  idle            run a shell that starts the screensaver when idle
+  version         print the version
+
+Run nemo <command> -h to see the flags of a command. The flags -world,
+-banner, -font, -timer and -scripts fall back to NEMO_WORLD, NEMO_BANNER,
+NEMO_FONT, NEMO_TIMER and NEMO_SCRIPTS. Flags win over the environment,
+which wins over the config file.
+`
+
+// fail prints the error and exits.
+func fail(format string, a ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", a...)
+	os.Exit(1)
+}
+
+// dirs is a flag that can be given more than once.
+type dirs []string
+
+func (d *dirs) String() string { return strings.Join(*d, ",") }
+
+func (d *dirs) Set(dir string) error {
+	*d = append(*d, dir)
+	return nil
+}
+
+// options are the flags of the commands that run the aquarium.
+type options struct {
+	swarm       int
+	tick        time.Duration
+	seed        int64
+	theme       string
+	config      string
+	assets      dirs
+	screensaver bool
+	duration    time.Duration
+	debug       bool
+	prof        bool
+	world       int
+	banner      string
+	font        string
+	timer       string
+	scripts     string
+	// the file the aquarium is recorded to, if any.
+	record string
+	// the names of the flags that were given.
+	set map[string]bool
+}
+
+// parseOptions parses the flags of the command. Commands that take a file
+// expect it after the flags.
+func parseOptions(cmd string, args []string, file bool) options {
+	o := options{set: map[string]bool{}}
+	d := config.Default()
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	fs.IntVar(&o.swarm, "swarm", *d.Swarm, "the amount of fish on every screen of the tank")
+	fs.DurationVar(&o.tick, "tick", time.Duration(*d.Tick), "the delay between two frames")
+	fs.Int64Var(&o.seed, "seed", 0, "the seed of all random choices, 0 picks a random one")
+	fs.StringVar(&o.theme, "theme", d.Theme, "the colors, one of "+strings.Join(layer.ThemeNames(), ", ")+" or a palette of the config")
+	fs.StringVar(&o.config, "config", config.Path(), "the config file, which is applied again whenever it changes")
+	fs.Var(&o.assets, "assets", "a directory with more assets and scripts, can be given more than once")
+	fs.BoolVar(&o.screensaver, "screensaver", false, "exit on any key or mouse event")
+	fs.DurationVar(&o.duration, "duration", 0, "exit after running this long, 0 runs forever")
+	fs.BoolVar(&o.debug, "debug", false, "write a debug log to nemo.log, like NEMO_DEBUG=1")
+	fs.BoolVar(&o.prof, "prof", false, "write cpu and memory profiles, like NEMO_PROF=1")
+	fs.IntVar(&o.world, "world", 1, "the width of the tank in screens, like NEMO_WORLD")
+	fs.StringVar(&o.banner, "banner", "", "the text that replaces the nemo logo, like NEMO_BANNER")
+	fs.StringVar(&o.font, "font", "", "the FIGlet font of the banner and the timer, like NEMO_FONT")
+	fs.StringVar(&o.timer, "timer", "", "the timer shown instead of the banner, like NEMO_TIMER")
+	fs.StringVar(&o.scripts, "scripts", "", "another directory with behavior scripts, like NEMO_SCRIPTS")
+	if file {
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: nemo %s [flags] <file>\n", cmd)
+			fs.PrintDefaults()
+		}
+	}
+	fs.Parse(args)
+	fs.Visit(func(f *flag.Flag) { o.set[f.Name] = true })
+	if file {
+		if fs.NArg() != 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		o.record = fs.Arg(0)
+	}
+	if world := os.Getenv("NEMO_WORLD"); world != "" && !o.set["world"] {
+		var err error
+		if o.world, err = strconv.Atoi(world); err != nil || o.world < 1 {
+			fail("Couldnt parse world: `%s` is not a positive number", world)
+		}
+	}
+	if !o.set["scripts"] {
+		o.scripts = os.Getenv("NEMO_SCRIPTS")
+	}
+	if o.swarm < 0 {
+		fail("Couldnt parse flags: -swarm must not be negative")
+	}
+	if o.world < 1 {
+		fail("Couldnt parse flags: -world must be positive")
+	}
+	if o.tick < renderer.MinTickDelay || o.tick > renderer.MaxTickDelay {
+		fail("Couldnt parse flags: -tick must be between %v and %v", renderer.MinTickDelay, renderer.MaxTickDelay)
+	}
+	return o
+}
+
+// overrides are the settings of the flags and environment variables that
+// were given, which replace the ones of the config file.
+func (o options) overrides() *config.Config {
+	c := &config.Config{}
+	if o.set["swarm"] {
+		c.Swarm = &o.swarm
+	}
+	if o.set["tick"] {
+		tick := config.Duration(o.tick)
+		c.Tick = &tick
+	}
+	if o.set["theme"] {
+		c.Theme = o.theme
+	}
+	if life := os.Getenv("NEMO_LIFE"); life != "" {
+		on := life == "1"
+		c.Behavior.Lifecycle = &on
+	}
+	c.Overlays.Banner = o.flagOrEnv("banner", o.banner, "NEMO_BANNER")
+	c.Overlays.Font = o.flagOrEnv("font", o.font, "NEMO_FONT")
+	c.Overlays.Timer = o.flagOrEnv("timer", o.timer, "NEMO_TIMER")
+	c.Behavior.Light = os.Getenv("NEMO_LIGHT")
+	c.Behavior.Current = os.Getenv("NEMO_CURRENT")
+	return c
+}
+
+// flagOrEnv is the value of the flag if it was given, or else the one of
+// the environment variable.
+func (o options) flagOrEnv(name string, value string, env string) string {
+	if o.set[name] {
+		return value
+	}
+	return os.Getenv(env)
+}
+
+// listAssets prints all the assets of the groups, including the ones in
+// the asset directories.
+func listAssets(args []string) {
+	var dirs dirs
+	fs := flag.NewFlagSet("assets", flag.ExitOnError)
+	fs.Var(&dirs, "assets", "a directory with more assets, can be given more than once")
+	fs.Parse(args)
+	for _, dir := range dirs {
+		if err := assets.LoadDir(dir); err != nil {
+			fail("Couldnt load assets: %v", err)
+		}
+	}
+	groups := assets.Groups()
+	if fs.NArg() > 0 {
+		groups = fs.Args()
+	}
+	for _, group := range groups {
+		all := assets.InGroup(group)
+		if len(all) == 0 {
+			fail("Couldnt list assets: group `%s` doesnt exist", group)
+		}
+		for _, a := range all {
+			fmt.Printf("%s/%s", a.Group, a.Species)
+			if a.Movement != "" {
+				fmt.Printf(" (%s)", a.Movement)
+			}
+			fmt.Println()
+			for y := 0; y < a.Height; y++ {
+				line := ""
+				for _, source := range a.Sources {
+					tile := ""
+					if y < len(source) {
+						tile = source[y]
+					}
+					line += fmt.Sprintf("  %-*s", a.Width, tile)
+				}
+				fmt.Println(strings.TrimRight(line, " "))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// replay plays a recording back until it ends or is interrupted.
+func replay(args []string) {
+	if len(args) != 1 {
+		fail("Usage: nemo replay <file>")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fail("Couldnt open recording: %v", err)
+	}
+	defer f.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := record.Replay(ctx, f, os.Stdout); err != nil {
+		fail("Couldnt replay recording: %v", err)
+	}
+}
+
+// idleShell runs a shell that starts the screensaver whenever it waited at its
+// prompt for a while. The flags after `--` are passed to the screensaver.
+func idleShell(args []string) {
+	fs := flag.NewFlagSet("idle", flag.ExitOnError)
+	after := fs.Duration("after", 5*time.Minute, "how long the shell waits for input before the screensaver starts")
+	shell := fs.String("shell", os.Getenv("SHELL"), "the shell to run")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: nemo idle [flags] [-- flags of the screensaver]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *after <= 0 {
+		fail("Couldnt parse flags: -after must be positive")
+	}
+	if *shell == "" {
+		*shell = "/bin/sh"
+	}
+	if os.Getenv("NEMO_IDLE") != "" {
+		fail("Couldnt start shell: nemo idle is running already")
+	}
+	// check the flags of the screensaver now, rather than every time it
+	// starts
+	saverArgs := append([]string{"run", "-screensaver"}, fs.Args()...)
+	parseOptions("run", saverArgs[1:], false)
+	exe, err := os.Executable()
+	if err != nil {
+		fail("Couldnt find nemo: %v", err)
+	}
+	cmd := exec.Command(*shell)
+	cmd.Env = append(os.Environ(), "NEMO_IDLE=1")
+	err = idle.Run(cmd, *after, func() *exec.Cmd { return exec.Command(exe, saverArgs...) })
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit):
+		os.Exit(exit.ExitCode())
+	case err != nil:
+		fail("Couldnt run shell: %v", err)
+	}
+}
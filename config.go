@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/lukasjoc/nemo/internal/assets"
+	"github.com/lukasjoc/nemo/internal/clock"
+	"github.com/lukasjoc/nemo/internal/config"
+	"github.com/lukasjoc/nemo/internal/current"
+	"github.com/lukasjoc/nemo/internal/keymap"
+	"github.com/lukasjoc/nemo/internal/lighting"
+	"github.com/lukasjoc/nemo/internal/renderer"
+	"github.com/lukasjoc/nemo/internal/text"
+)
+
+// reload is posted to the screen whenever the config file changed.
+type reload struct {
+	file *config.Config
+	err  error
+}
+
+// settings merges the defaults, the config file and the flags and
+// environment variables, in that order. The keys of the config replace the
+// ones of the keymap file.
+func settings(file *config.Config, o options) (*config.Config, keymap.Keymap, error) {
+	c := config.Default().Merge(file).Merge(o.overrides())
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	keys, err := keymap.Load(keymap.Path())
+	if err != nil {
+		return nil, nil, err
+	}
+	if keys, err = keys.With(c.Keys); err != nil {
+		return nil, nil, err
+	}
+	return c, keys, nil
+}
+
+func (next reload) settings(o options) (*config.Config, keymap.Keymap, error) {
+	if next.err != nil {
+		return nil, nil, next.err
+	}
+	return settings(next.file, o)
+}
+
+// apply changes the settings of the renderer to the ones of the config,
+// without restarting it. Only what changed since the previous config is
+// applied, so the fish that were added or a speed that was changed by hand
+// stay that way. Without a previous config everything is applied.
+func apply(r *renderer.Renderer, prev *config.Config, c *config.Config, scale int) {
+	first := prev == nil
+	// the config is validated already
+	theme, _ := c.LookupTheme()
+	light, _ := lighting.Parse(c.Behavior.Light)
+	cur, _ := current.Parse(c.Behavior.Current)
+	timer, _ := clock.Parse(c.Overlays.Timer)
+	bannerChanged := first || prev.Overlays.Banner != c.Overlays.Banner || prev.Overlays.Font != c.Overlays.Font
+	var font *text.Font
+	if bannerChanged {
+		font, _ = text.LoadFont(c.Overlays.Font)
+	}
+	r.Configure(func() {
+		theme.Use()
+		assets.SetWeights("fish", c.Species)
+		r.BubbleRate = *c.Behavior.BubbleRate
+		r.Lifecycle = *c.Behavior.Lifecycle
+		if first || *prev.Tick != *c.Tick {
+			r.TickDelay = time.Duration(*c.Tick)
+		}
+		if first || prev.Behavior.Light != c.Behavior.Light {
+			r.Light = light
+		}
+		if first || prev.Behavior.Current != c.Behavior.Current {
+			r.Current = cur
+		}
+		if bannerChanged {
+			r.Font = font
+			r.Banner = renderer.NameTiles
+			if c.Overlays.Banner != "" {
+				r.Banner = font.Render(c.Overlays.Banner)
+			}
+		}
+		// NOTE: a timer that was switched by hand keeps running as long as
+		// the config doesnt change it
+		if first || prev.Overlays.Timer != c.Overlays.Timer {
+			r.Timer = timer
+		}
+	})
+	if first || *prev.Swarm != *c.Swarm {
+		r.SetSwarmSize(*c.Swarm * scale)
+	}
+	if first || *prev.Overlays.Stats != *c.Overlays.Stats {
+		r.SetStats(*c.Overlays.Stats)
+	}
+	if first || *prev.Overlays.Minimap != *c.Overlays.Minimap {
+		r.SetMinimap(*c.Overlays.Minimap)
+	}
+	if first || prev.Theme != c.Theme || !reflect.DeepEqual(prev.Palettes[c.Theme], c.Palettes[c.Theme]) {
+		r.Restyle()
+	}
+	r.SetNotice(nil)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/config"
+	"github.com/lukasjoc/nemo/internal/keymap"
+)
+
+func TestSettings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "nemo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	keys := []byte(`{"pause": ["P"], "step": ["S"]}`)
+	if err := os.WriteFile(filepath.Join(dir, "nemo", "keys.json"), keys, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := config.Parse([]byte(`{"swarm": 24, "theme": "ocean", "keys": {"pause": ["Space"]}, "overlays": {"banner": "file", "timer": "clock"}, "behavior": {"light": "fast"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		swarm   int
+		theme   string
+		light   string
+		banner  string
+		timer   string
+		world   int
+		scripts string
+	}{
+		{"the file", nil, nil, 24, "ocean", "fast", "file", "clock", 1, ""},
+		{"flags", []string{"-swarm", "7", "-theme", "neon"}, nil, 7, "neon", "fast", "file", "clock", 1, ""},
+		{"flags of the defaults", []string{"-swarm", "18"}, nil, 18, "ocean", "fast", "file", "clock", 1, ""},
+		{
+			"environment", nil,
+			map[string]string{"NEMO_LIGHT": "clock", "NEMO_BANNER": "env", "NEMO_TIMER": "stopwatch", "NEMO_WORLD": "2", "NEMO_SCRIPTS": "env"},
+			24, "ocean", "clock", "env", "stopwatch", 2, "env",
+		},
+		{
+			"flags over the environment",
+			[]string{"-banner", "flag", "-timer", "countdown:5m", "-world", "3", "-scripts", "flag"},
+			map[string]string{"NEMO_BANNER": "env", "NEMO_TIMER": "stopwatch", "NEMO_WORLD": "2", "NEMO_SCRIPTS": "env"},
+			24, "ocean", "fast", "flag", "countdown:5m", 3, "flag",
+		},
+	}
+	for _, tt := range tests {
+		for _, k := range []string{"NEMO_LIFE", "NEMO_LIGHT", "NEMO_CURRENT", "NEMO_BANNER", "NEMO_FONT", "NEMO_TIMER", "NEMO_WORLD", "NEMO_SCRIPTS"} {
+			t.Setenv(k, tt.env[k])
+		}
+		o := parseOptions("run", tt.args, false)
+		c, keys, err := settings(file, o)
+		if err != nil {
+			t.Errorf("%s: error = %v", tt.name, err)
+			continue
+		}
+		if *c.Swarm != tt.swarm || c.Theme != tt.theme || c.Behavior.Light != tt.light {
+			t.Errorf("%s: settings = %d %q %q, want %d %q %q", tt.name, *c.Swarm, c.Theme, c.Behavior.Light, tt.swarm, tt.theme, tt.light)
+		}
+		if c.Overlays.Banner != tt.banner || c.Overlays.Timer != tt.timer {
+			t.Errorf("%s: overlays = %q %q, want %q %q", tt.name, c.Overlays.Banner, c.Overlays.Timer, tt.banner, tt.timer)
+		}
+		if o.world != tt.world || o.scripts != tt.scripts {
+			t.Errorf("%s: world and scripts = %d %q, want %d %q", tt.name, o.world, o.scripts, tt.world, tt.scripts)
+		}
+		// the keys of the config replace the ones of the keymap file
+		for key, want := range map[keymap.Key]keymap.Action{
+			{Key: tcell.KeyRune, Rune: ' '}: keymap.Pause,
+			{Key: tcell.KeyRune, Rune: 'S'}: keymap.Step,
+		} {
+			if keys[key] != want {
+				t.Errorf("%s: key %v = %q, want %q", tt.name, key, keys[key], want)
+			}
+		}
+		if action, ok := keys[keymap.Key{Key: tcell.KeyRune, Rune: 'P'}]; ok {
+			t.Errorf("%s: key P is still bound to %q", tt.name, action)
+		}
+	}
+}
@@ -2,13 +2,18 @@ module github.com/lukasjoc/nemo
 
 go 1.22.0
 
-require github.com/gdamore/tcell v1.4.0
+require (
+	github.com/creack/pty v1.1.24
+	github.com/gdamore/tcell v1.4.0
+	go.starlark.net v0.0.0-20240725214946-42030a7cedce
+	golang.org/x/sys v0.20.0
+	golang.org/x/term v0.20.0
+)
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
 )
@@ -2,16 +2,31 @@ package assets
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/lukasjoc/nemo/internal"
 )
 
+// The index of the optional source that is shown while an entity turns
+// around. The first two sources are always facing right and left.
+const TurnFrame = 2
+
 type Asset struct {
-	Group   string
+	Group string
+	// The name of the species, which is the same as the group for assets
+	// that have no species.
+	Species string
 	Sources [][]string
 	Width   int
 	Height  int
+	// The name of the movement model entities using this asset should follow.
+	// Empty means the default model of the entity kind is used.
+	Movement string
+	// The path of the behavior script of the species, if it has one.
+	Script string
 }
 
 var cache = map[string][]Asset{}
@@ -24,6 +39,16 @@ func toTiles(sources []string) [][]string {
 	return t
 }
 
+// trimmed drops the empty lines around the sources, so assets that stand on
+// the floor dont hover above it.
+func trimmed(sources ...string) []string {
+	t := []string{}
+	for _, s := range sources {
+		t = append(t, strings.Trim(s, "\n"))
+	}
+	return t
+}
+
 func longestTile(a []string) int {
 	n := -1
 	for _, t := range a {
@@ -35,12 +60,18 @@ func longestTile(a []string) int {
 }
 
 func newAsset(group string, sources ...string) Asset {
+	return newSpecies(group, group, "", sources...)
+}
+
+func newSpecies(group string, species string, movement string, sources ...string) Asset {
 	tiles := toTiles(sources)
 	a := Asset{
-		Group:   group,
-		Sources: tiles,
-		Width:   longestTile(tiles[0]),
-		Height:  len(tiles[0]),
+		Group:    group,
+		Species:  species,
+		Sources:  tiles,
+		Width:    longestTile(tiles[0]),
+		Height:   len(tiles[0]),
+		Movement: movement,
 	}
 	if _, ok := cache[a.Group]; !ok {
 		cache[a.Group] = []Asset{}
@@ -49,16 +80,155 @@ func newAsset(group string, sources ...string) Asset {
 	return a
 }
 
+// Find looks up the asset of a species within a group.
+func Find(group string, species string) (Asset, bool) {
+	for _, a := range cache[group] {
+		if a.Species == species {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Species lists the names of all the species within a group.
+func Species(group string) []string {
+	names := []string{}
+	for _, a := range cache[group] {
+		names = append(names, a.Species)
+	}
+	return names
+}
+
+// Groups lists the names of all the groups.
+func Groups() []string {
+	names := []string{}
+	for group := range cache {
+		names = append(names, group)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// InGroup lists all the assets within a group.
+func InGroup(group string) []Asset { return cache[group] }
+
+// The extensions of the asset files and the behavior scripts in an asset
+// directory.
+const (
+	Ext       = ".txt"
+	ScriptExt = ".star"
+)
+
+// LoadDir adds the assets of a directory, which has a directory for every
+// group with a file for every species in it, like `fish/clownfish.txt`.
+// The sources within a file are separated by a line with `---` and the file
+// might start with a line like `movement: dart` to pick the movement model.
+// A behavior script next to it, like `fish/clownfish.star`, drives the
+// species, which might be a built in one as well.
+func LoadDir(dir string) error {
+	for _, group := range Groups() {
+		files, err := filepath.Glob(filepath.Join(dir, group, "*"+Ext))
+		if err != nil {
+			return err
+		}
+		for _, path := range files {
+			if err := loadFile(group, path); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+		scripts, err := filepath.Glob(filepath.Join(dir, group, "*"+ScriptExt))
+		if err != nil {
+			return err
+		}
+		for _, path := range scripts {
+			if err := setScript(group, path); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setScript lets the script drive the species it is named after.
+func setScript(group string, path string) error {
+	species := strings.TrimSuffix(filepath.Base(path), ScriptExt)
+	for i, a := range cache[group] {
+		if a.Species == species {
+			cache[group][i].Script = path
+			return nil
+		}
+	}
+	return fmt.Errorf("species `%s` doesnt exist", species)
+}
+
+func loadFile(group string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	movement := ""
+	if first, rest, ok := strings.Cut(content, "\n"); ok && strings.HasPrefix(first, "movement:") {
+		movement = strings.TrimSpace(strings.TrimPrefix(first, "movement:"))
+		content = rest
+	}
+	sources := []string{}
+	for _, source := range strings.Split(content, "\n---\n") {
+		if source = strings.Trim(source, "\n"); source != "" {
+			sources = append(sources, source)
+		}
+	}
+	switch {
+	case len(sources) == 0:
+		return fmt.Errorf("has no sources")
+	// everything but food and bubbles faces right and left
+	case len(sources) < 2 && group != "food" && group != "bubble":
+		return fmt.Errorf("needs a source facing right and one facing left")
+	}
+	species := strings.TrimSuffix(filepath.Base(path), Ext)
+	newSpecies(group, species, movement, sources...)
+	return nil
+}
+
+func (a Asset) HasTurnFrame() bool { return len(a.Sources) > TurnFrame }
+
+// The weights of the species by group, see `SetWeights`.
+var weights = map[string]map[string]int{}
+
+// SetWeights makes the species of a group as likely to be picked by Random
+// as their weight. Species without a weight weigh 1.
+func SetWeights(group string, w map[string]int) { weights[group] = w }
+
+func weight(a Asset) int {
+	if w, ok := weights[a.Group][a.Species]; ok {
+		return w
+	}
+	return 1
+}
+
 func Random(group string) Asset {
 	if _, ok := cache[group]; !ok {
 		panic(fmt.Sprintf("group with name `%s` doesnt exist", group))
 	}
-	return internal.Choose(cache[group]...)
+	total := 0
+	for _, a := range cache[group] {
+		total += weight(a)
+	}
+	if len(weights[group]) == 0 || total == 0 {
+		return internal.Choose(cache[group]...)
+	}
+	n := internal.IntRand(total)
+	for _, a := range cache[group] {
+		if n -= weight(a); n < 0 {
+			return a
+		}
+	}
+	return cache[group][len(cache[group])-1]
 }
 
 // DISCLAIMER: some of the fish are taken from the asciiquarium program
 
-var _ = newAsset("fish", `
+var _ = newSpecies("fish", "tetra", "", `
   __
 \/ @\
 /\__/
@@ -66,10 +236,14 @@ var _ = newAsset("fish", `
  __
 /@ \/
 \__/\
+`, `
+ __
+|@@|
+|__|
 `,
 )
 
-var _ = newAsset("fish", `
+var _ = newSpecies("fish", "grouper", "turn", `
   ___
 \/ CC\
 /\__~/
@@ -77,12 +251,16 @@ var _ = newAsset("fish", `
  ___
 /CC \/
 \~__/\
+`, `
+ ___
+|CC |
+|~__|
 `,
 )
 
-var _ = newAsset("fish", `>(#)@>`, `<@(#)<`)
+var _ = newSpecies("fish", "minnow", "dart", `>(#)@>`, `<@(#)<`, ` (#@) `)
 
-var _ = newAsset("fish", `
+var _ = newSpecies("fish", "angelfish", "", `
        \
      ...\..,
 \  /'       \
@@ -99,7 +277,7 @@ var _ = newAsset("fish", `
 `,
 )
 
-var _ = newAsset("fish", `
+var _ = newSpecies("fish", "pufferfish", "bob", `
     \
 \ /--\
 >=  (o>
@@ -114,7 +292,7 @@ var _ = newAsset("fish", `
 `,
 )
 
-var _ = newAsset("fish", `
+var _ = newSpecies("fish", "mackerel", "", `
        \:.
 \;,   ,;\\\\\,,
   \\\\\;;:::::::o
@@ -129,7 +307,92 @@ var _ = newAsset("fish", `
 `,
 )
 
-var _ = newAsset("bubble", `*`)
-var _ = newAsset("bubble", `.`)
-var _ = newAsset("bubble", `o`)
-var _ = newAsset("bubble", `O`)
+var _ = newSpecies("fish", "sardine", "wander", `><(('>`, `<'))><`)
+
+var _ = newSpecies("fish", "jellyfish", "pulse", `
+ .--.
+(    )
+ ))((
+ ((  )
+`, `
+ .--.
+(    )
+ ))((
+ ((  )
+`,
+)
+
+// NOTE: fry are the young of every fish species before they grow up.
+var _ = newAsset("fry", `><>`, `<><`)
+
+// NOTE: glowing creatures are bioluminescent and only come out at night.
+
+var _ = newSpecies("glow", "lanternfish", "", `
+     *
+  __/
+\/  o\
+/\___/
+`, `
+*
+ \__
+/o  \/
+\___/\
+`,
+)
+
+var _ = newSpecies("glow", "comb jelly", "pulse", `
+ .**.
+(    )
+ :;:;
+ ;  :
+`, `
+ .**.
+(    )
+ :;:;
+ ;  :
+`,
+)
+
+var _ = newAsset("food", `,`)
+var _ = newAsset("food", `*`)
+var _ = newAsset("food", `'`)
+
+// NOTE: bubbles have no facing, the sources are the stages a bubble grows
+// through while it rises to the surface.
+var _ = newAsset("bubble", `.`, `o`, `O`)
+
+// NOTE: walkers are bottom dwellers that walk along the floor. Like the fish
+// the first source faces right and the second one faces left.
+
+var _ = newSpecies("walker", "crab", "", trimmed(`
+ _  _
+(o\/o)
+//  \\
+`, `
+ _  _
+(o\/o)
+//  \\
+`,
+)...)
+
+var _ = newSpecies("walker", "snail", "", trimmed(`
+  .-.  \/
+ ( @ )_/
+ '---''
+`, `
+\/  .-.
+ \_( @ )
+  ''---'
+`,
+)...)
+
+var _ = newSpecies("walker", "starfish", "", trimmed(`
+  ,
+-=*=-
+ / \
+`, `
+  ,
+-=*=-
+ / \
+`,
+)...)
@@ -0,0 +1,211 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// keep restores the assets of the groups once the test is done.
+func keep(t *testing.T, groups ...string) {
+	for _, group := range groups {
+		before := slices.Clone(cache[group])
+		t.Cleanup(func() { cache[group] = before })
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    string
+		data     string
+		movement string
+		sources  [][]string
+		err      bool
+	}{
+		{
+			name:    "right and left",
+			group:   "fish",
+			data:    "><>\n---\n<><\n",
+			sources: [][]string{{"><>"}, {"<><"}},
+		},
+		{
+			name:     "movement",
+			group:    "fish",
+			data:     "movement: dart\n ,\n><>\n---\n ,\n<><\n",
+			movement: "dart",
+			sources:  [][]string{{" ,", "><>"}, {" ,", "<><"}},
+		},
+		{
+			name:    "windows line endings",
+			group:   "fish",
+			data:    "><>\r\n---\r\n<><\r\n",
+			sources: [][]string{{"><>"}, {"<><"}},
+		},
+		{
+			name:    "single source",
+			group:   "food",
+			data:    "~\n",
+			sources: [][]string{{"~"}},
+		},
+		{
+			name:  "no left source",
+			group: "fish",
+			data:  "><>\n",
+			err:   true,
+		},
+		{
+			name:  "empty",
+			group: "food",
+			data:  "\n\n",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep(t, tt.group)
+			path := filepath.Join(t.TempDir(), "test"+Ext)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := loadFile(tt.group, path)
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want error %t", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			a, ok := Find(tt.group, "test")
+			if !ok {
+				t.Fatalf("species isnt added")
+			}
+			if a.Movement != tt.movement || !slices.EqualFunc(a.Sources, tt.sources, slices.Equal) {
+				t.Errorf("asset = %q %q, want %q %q", a.Movement, a.Sources, tt.movement, tt.sources)
+			}
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	t.Cleanup(func() { SetWeights("fish", nil) })
+	species := Species("fish")
+	// weighs nothing but the given species
+	only := func(names ...string) map[string]int {
+		w := map[string]int{}
+		for _, s := range species {
+			w[s] = 0
+		}
+		for _, s := range names {
+			w[s] = 1
+		}
+		return w
+	}
+	tests := []struct {
+		name    string
+		weights map[string]int
+		// how often every species is picked within 1000 picks, at least
+		// and at most
+		want map[string][2]int
+	}{
+		{
+			name:    "only one",
+			weights: only(species[0]),
+			want:    map[string][2]int{species[0]: {1000, 1000}},
+		},
+		{
+			name:    "two",
+			weights: only(species[0], species[1]),
+			want:    map[string][2]int{species[0]: {400, 600}, species[1]: {400, 600}, species[2]: {0, 0}},
+		},
+		{
+			name:    "left out",
+			weights: map[string]int{species[0]: 0},
+			want:    map[string][2]int{species[0]: {0, 0}, species[1]: {1, 1000}},
+		},
+		{
+			name:    "heavier",
+			weights: map[string]int{species[0]: 100},
+			want:    map[string][2]int{species[0]: {800, 1000}},
+		},
+		{
+			name:    "all zero",
+			weights: only(),
+			want:    map[string][2]int{species[0]: {1, 1000}},
+		},
+	}
+	for _, tt := range tests {
+		SetWeights("fish", tt.weights)
+		picked := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			picked[Random("fish").Species]++
+		}
+		for s, bounds := range tt.want {
+			if n := picked[s]; n < bounds[0] || n > bounds[1] {
+				t.Errorf("%s: %s is picked %d times, want %d to %d", tt.name, s, n, bounds[0], bounds[1])
+			}
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		// the scripts of the species that should be picked up
+		scripts map[string]string
+		err     bool
+	}{
+		{
+			name:    "script next to the asset",
+			files:   map[string]string{"fish/test.txt": "><>\n---\n<><\n", "fish/test.star": ""},
+			scripts: map[string]string{"test": "fish/test.star"},
+		},
+		{
+			name:    "script of a built in species",
+			files:   map[string]string{"fish/sardine.star": ""},
+			scripts: map[string]string{"sardine": "fish/sardine.star"},
+		},
+		{
+			name:    "asset without a script",
+			files:   map[string]string{"fish/test.txt": "><>\n---\n<><\n"},
+			scripts: map[string]string{"test": ""},
+		},
+		{
+			name:  "script of an unknown species",
+			files: map[string]string{"fish/nemo.star": ""},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep(t, "fish")
+			dir := t.TempDir()
+			for name, data := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := LoadDir(dir)
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want error %t", err, tt.err)
+			}
+			for species, script := range tt.scripts {
+				a, ok := Find("fish", species)
+				if !ok {
+					t.Fatalf("species `%s` doesnt exist", species)
+				}
+				if script != "" {
+					script = filepath.Join(dir, script)
+				}
+				if a.Script != script {
+					t.Errorf("%s: script = %q, want %q", species, a.Script, script)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package clock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Mode int
+
+const (
+	Off Mode = iota
+	// Shows the time of day.
+	Clock
+	// Counts up from when it was started.
+	Stopwatch
+	// Counts down the durations one after another.
+	Countdown
+)
+
+// The durations of a pomodoro timer, which alternates between work and a
+// short break.
+var Pomodoro = []time.Duration{25 * time.Minute, 5 * time.Minute}
+
+// A Timer is a clock, stopwatch or countdown that is shown instead of the
+// banner.
+type Timer struct {
+	Mode Mode
+	// The durations of the countdown. A single duration stops at zero, more
+	// of them repeat forever like a pomodoro timer.
+	Durations []time.Duration
+	start     time.Time
+	// the current duration of the countdown and if it ran out already.
+	round int
+	rang  bool
+}
+
+func New(mode Mode, durations ...time.Duration) *Timer {
+	if len(durations) == 0 {
+		durations = Pomodoro
+	}
+	return &Timer{Mode: mode, Durations: durations, start: time.Now()}
+}
+
+// Parse parses `clock`, `stopwatch`, `countdown:<duration>` or
+// `pomodoro[:<work>,<break>]`. An empty spec is a timer that is off.
+func Parse(spec string) (*Timer, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	switch name {
+	case "":
+		return New(Off), nil
+	case "clock":
+		return New(Clock), nil
+	case "stopwatch":
+		return New(Stopwatch), nil
+	case "countdown", "pomodoro":
+		if arg == "" && name == "pomodoro" {
+			return New(Countdown), nil
+		}
+		durations := []time.Duration{}
+		for _, s := range strings.Split(arg, ",") {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid duration `%s`", s)
+			}
+			durations = append(durations, d)
+		}
+		return New(Countdown, durations...), nil
+	}
+	return nil, fmt.Errorf("unknown timer `%s`", name)
+}
+
+// Start starts the stopwatch or countdown over again.
+func (t *Timer) Start(now time.Time) {
+	t.start = now
+	t.round = 0
+	t.rang = false
+}
+
+// Next switches to the next mode and starts it.
+func (t *Timer) Next(now time.Time) {
+	t.Mode = (t.Mode + 1) % (Countdown + 1)
+	t.Start(now)
+}
+
+// Update moves the countdown on to the next duration and reports if the
+// current one just ran out.
+func (t *Timer) Update(now time.Time) bool {
+	if t.Mode != Countdown || t.rang || now.Before(t.start.Add(t.current())) {
+		return false
+	}
+	if len(t.Durations) > 1 {
+		t.start = t.start.Add(t.current())
+		t.round = (t.round + 1) % len(t.Durations)
+	} else {
+		t.rang = true
+	}
+	return true
+}
+
+func (t *Timer) current() time.Duration { return t.Durations[t.round] }
+
+// Text is what the timer shows.
+func (t *Timer) Text(now time.Time) string {
+	switch t.Mode {
+	case Clock:
+		return now.Format("15:04")
+	case Stopwatch:
+		return format(now.Sub(t.start))
+	case Countdown:
+		// count down whole seconds, so it only shows zero once it ran out
+		left := max(t.start.Add(t.current()).Sub(now), 0)
+		return format(left + time.Second - 1)
+	}
+	return ""
+}
+
+// format shows a duration as minutes and seconds, with hours if needed.
+func format(d time.Duration) string {
+	s := int(d.Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
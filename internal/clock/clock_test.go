@@ -0,0 +1,88 @@
+package clock
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec      string
+		mode      Mode
+		durations []time.Duration
+		err       bool
+	}{
+		{"", Off, Pomodoro, false},
+		{"clock", Clock, Pomodoro, false},
+		{"stopwatch", Stopwatch, Pomodoro, false},
+		{"countdown:90s", Countdown, []time.Duration{90 * time.Second}, false},
+		{"pomodoro", Countdown, Pomodoro, false},
+		{"pomodoro:50m,10m", Countdown, []time.Duration{50 * time.Minute, 10 * time.Minute}, false},
+		{"countdown", Off, nil, true},
+		{"countdown:0s", Off, nil, true},
+		{"countdown:-1m", Off, nil, true},
+		{"pomodoro:25m,", Off, nil, true},
+		{"alarm", Off, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.spec)
+		if (err != nil) != tt.err {
+			t.Errorf("Parse(%q) error = %v, want error %t", tt.spec, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Mode != tt.mode || !slices.Equal(got.Durations, tt.durations) {
+			t.Errorf("Parse(%q) = %v %v, want %v %v", tt.spec, got.Mode, got.Durations, tt.mode, tt.durations)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		timer *Timer
+		// the seconds since the start at which the timer is updated
+		at   []int
+		rang []bool
+		text string
+	}{
+		{
+			name:  "countdown",
+			timer: New(Countdown, time.Minute),
+			at:    []int{0, 59, 60, 61, 120},
+			rang:  []bool{false, false, true, false, false},
+			text:  "00:00",
+		},
+		{
+			name:  "pomodoro",
+			timer: New(Countdown, 2*time.Minute, time.Minute),
+			at:    []int{119, 120, 150, 180, 181},
+			rang:  []bool{false, true, false, true, false},
+			text:  "01:59",
+		},
+		{
+			name:  "stopwatch",
+			timer: New(Stopwatch),
+			at:    []int{3600, 7200},
+			rang:  []bool{false, false},
+			text:  "2:00:00",
+		},
+	}
+	for _, tt := range tests {
+		tt.timer.Start(start)
+		now := start
+		for i, s := range tt.at {
+			now = start.Add(time.Duration(s) * time.Second)
+			if got := tt.timer.Update(now); got != tt.rang[i] {
+				t.Errorf("%s: Update after %ds = %t, want %t", tt.name, s, got, tt.rang[i])
+			}
+		}
+		if got := tt.timer.Text(now); got != tt.text {
+			t.Errorf("%s: Text = %q, want %q", tt.name, got, tt.text)
+		}
+	}
+}
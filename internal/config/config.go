@@ -0,0 +1,322 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/assets"
+	"github.com/lukasjoc/nemo/internal/clock"
+	"github.com/lukasjoc/nemo/internal/current"
+	"github.com/lukasjoc/nemo/internal/keymap"
+	"github.com/lukasjoc/nemo/internal/layer"
+	"github.com/lukasjoc/nemo/internal/lighting"
+	"github.com/lukasjoc/nemo/internal/renderer"
+	"github.com/lukasjoc/nemo/internal/text"
+)
+
+// A Config is everything that can be set in the config file. Whatever is
+// left out keeps its default, see `Default`.
+type Config struct {
+	// The amount of fish on every screen of the tank.
+	Swarm *int `json:"swarm"`
+	// The delay between two frames, like `120ms`.
+	Tick *Duration `json:"tick"`
+	// The name of one of the built in themes or palettes.
+	Theme string `json:"theme"`
+	// How likely every species of fish is to swim in. Species without a
+	// weight weigh 1.
+	Species map[string]int `json:"species"`
+	// More themes by their name.
+	Palettes map[string]Palette `json:"palettes"`
+	// The keys of the actions, which replace the default keys of them.
+	Keys     map[keymap.Action][]string `json:"keys"`
+	Overlays Overlays                   `json:"overlays"`
+	Behavior Behavior                   `json:"behavior"`
+}
+
+// A Palette is a theme made of color names or hex colors like `#ff8800`.
+// The blues and glows are the colors if they are left out.
+type Palette struct {
+	Colors []string `json:"colors"`
+	Blues  []string `json:"blues"`
+	Glows  []string `json:"glows"`
+	Plain  bool     `json:"plain"`
+}
+
+type Overlays struct {
+	Stats   *bool `json:"stats"`
+	Minimap *bool `json:"minimap"`
+	// The text that replaces the nemo logo, like `NEMO_BANNER`.
+	Banner string `json:"banner"`
+	// The font of the banner and the timer, like `NEMO_FONT`.
+	Font string `json:"font"`
+	// The timer that is shown instead of the banner, like `NEMO_TIMER`.
+	Timer string `json:"timer"`
+}
+
+type Behavior struct {
+	// Simulates the lifecycle of the fish, like `NEMO_LIFE=1`.
+	Lifecycle *bool `json:"lifecycle"`
+	// The lighting, like `NEMO_LIGHT`.
+	Light string `json:"light"`
+	// The water current, like `NEMO_CURRENT`.
+	Current string `json:"current"`
+	// The average amount of ticks between two bubbles of the same fish.
+	BubbleRate *int `json:"bubble-rate"`
+}
+
+// Duration is a duration written like `120ms` or `1s`.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like `120ms`")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration `%s`", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// Default is the config with every setting at its default.
+func Default() *Config {
+	return &Config{
+		Swarm: ptr(18),
+		Tick:  ptr(Duration(renderer.DefaultTickDelay)),
+		Theme: "default",
+		Overlays: Overlays{
+			Stats:   ptr(false),
+			Minimap: ptr(true),
+		},
+		Behavior: Behavior{
+			Lifecycle:  ptr(false),
+			BubbleRate: ptr(renderer.DefaultBubbleRate),
+		},
+	}
+}
+
+// Path is where the config is loaded from by default.
+func Path() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "nemo", "config.json")
+}
+
+// Parse parses and validates a config in JSON. Unknown settings are an
+// error, so typos dont go unnoticed.
+func Parse(data []byte) (*Config, error) {
+	c := Config{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Load loads the config from a file. A file that doesnt exist is an empty
+// config.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || path == "" {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Merge returns a config with the settings of the other config replacing
+// the ones of this config.
+func (c *Config) Merge(other *Config) *Config {
+	m := *c
+	if other.Swarm != nil {
+		m.Swarm = other.Swarm
+	}
+	if other.Tick != nil {
+		m.Tick = other.Tick
+	}
+	if other.Theme != "" {
+		m.Theme = other.Theme
+	}
+	if other.Species != nil {
+		m.Species = other.Species
+	}
+	if other.Palettes != nil {
+		m.Palettes = other.Palettes
+	}
+	if other.Keys != nil {
+		m.Keys = other.Keys
+	}
+	if other.Overlays.Stats != nil {
+		m.Overlays.Stats = other.Overlays.Stats
+	}
+	if other.Overlays.Minimap != nil {
+		m.Overlays.Minimap = other.Overlays.Minimap
+	}
+	if other.Overlays.Banner != "" {
+		m.Overlays.Banner = other.Overlays.Banner
+	}
+	if other.Overlays.Font != "" {
+		m.Overlays.Font = other.Overlays.Font
+	}
+	if other.Overlays.Timer != "" {
+		m.Overlays.Timer = other.Overlays.Timer
+	}
+	if other.Behavior.Lifecycle != nil {
+		m.Behavior.Lifecycle = other.Behavior.Lifecycle
+	}
+	if other.Behavior.Light != "" {
+		m.Behavior.Light = other.Behavior.Light
+	}
+	if other.Behavior.Current != "" {
+		m.Behavior.Current = other.Behavior.Current
+	}
+	if other.Behavior.BubbleRate != nil {
+		m.Behavior.BubbleRate = other.Behavior.BubbleRate
+	}
+	return &m
+}
+
+// Validate reports the first setting that is invalid.
+func (c *Config) Validate() error {
+	if c.Swarm != nil && *c.Swarm < 0 {
+		return fmt.Errorf("swarm must not be negative")
+	}
+	if d := c.Tick; d != nil && (time.Duration(*d) < renderer.MinTickDelay || time.Duration(*d) > renderer.MaxTickDelay) {
+		return fmt.Errorf("tick must be between %v and %v", renderer.MinTickDelay, renderer.MaxTickDelay)
+	}
+	for name, p := range c.Palettes {
+		if _, err := p.Theme(); err != nil {
+			return fmt.Errorf("palette `%s`: %v", name, err)
+		}
+	}
+	if _, err := c.LookupTheme(); err != nil {
+		return err
+	}
+	for species, weight := range c.Species {
+		if _, ok := assets.Find("fish", species); !ok {
+			return fmt.Errorf("species `%s` doesnt exist", species)
+		}
+		if weight < 0 {
+			return fmt.Errorf("species `%s` must not have a negative weight", species)
+		}
+	}
+	if _, err := keymap.Default().With(c.Keys); err != nil {
+		return fmt.Errorf("keys: %v", err)
+	}
+	if _, err := text.LoadFont(c.Overlays.Font); err != nil {
+		return fmt.Errorf("overlays: %v", err)
+	}
+	if _, err := clock.Parse(c.Overlays.Timer); err != nil {
+		return fmt.Errorf("overlays: %v", err)
+	}
+	if _, err := lighting.Parse(c.Behavior.Light); err != nil {
+		return fmt.Errorf("behavior: %v", err)
+	}
+	if _, err := current.Parse(c.Behavior.Current); err != nil {
+		return fmt.Errorf("behavior: %v", err)
+	}
+	if r := c.Behavior.BubbleRate; r != nil && *r <= 0 {
+		return fmt.Errorf("behavior: bubble-rate must be positive")
+	}
+	return nil
+}
+
+// LookupTheme looks up the theme of the config within the palettes and the
+// built in themes. No theme at all is the default theme.
+func (c *Config) LookupTheme() (layer.Theme, error) {
+	if p, ok := c.Palettes[c.Theme]; ok {
+		return p.Theme()
+	}
+	name := c.Theme
+	if name == "" {
+		name = "default"
+	}
+	t, ok := layer.Themes[name]
+	if !ok {
+		return layer.Theme{}, fmt.Errorf("unknown theme `%s`", c.Theme)
+	}
+	return t, nil
+}
+
+// Theme creates the theme of the palette.
+func (p Palette) Theme() (layer.Theme, error) {
+	if len(p.Colors) == 0 {
+		return layer.Theme{}, fmt.Errorf("needs at least one color")
+	}
+	colors, err := parseColors(p.Colors)
+	if err != nil {
+		return layer.Theme{}, err
+	}
+	blues, glows := colors, colors
+	if len(p.Blues) > 0 {
+		if blues, err = parseColors(p.Blues); err != nil {
+			return layer.Theme{}, err
+		}
+	}
+	if len(p.Glows) > 0 {
+		if glows, err = parseColors(p.Glows); err != nil {
+			return layer.Theme{}, err
+		}
+	}
+	return layer.NewTheme(colors, blues, glows, p.Plain), nil
+}
+
+func parseColors(names []string) ([]tcell.Color, error) {
+	colors := []tcell.Color{}
+	for _, name := range names {
+		c := tcell.GetColor(name)
+		if c == tcell.ColorDefault {
+			return nil, fmt.Errorf("unknown color `%s`", name)
+		}
+		colors = append(colors, c)
+	}
+	return colors, nil
+}
+
+// The interval the config file is checked for changes in.
+const PollInterval = time.Second
+
+// Watch calls the function with the config every time the file changes,
+// or with the error if it couldnt be loaded. It never stops.
+func Watch(path string, fn func(*Config, error)) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+	go func() {
+		mod, size := stat()
+		for range time.Tick(PollInterval) {
+			nextMod, nextSize := stat()
+			if nextMod.Equal(mod) && nextSize == size {
+				continue
+			}
+			mod, size = nextMod, nextSize
+			fn(Load(path))
+		}
+	}()
+}
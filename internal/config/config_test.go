@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lukasjoc/nemo/internal/assets"
+)
+
+func TestParse(t *testing.T) {
+	fish := assets.Species("fish")[0]
+	tests := []struct {
+		name string
+		data string
+		err  bool
+	}{
+		{"empty", `{}`, false},
+		{"everything", `{
+			"swarm": 24,
+			"tick": "100ms",
+			"theme": "sunset",
+			"species": {"` + fish + `": 3},
+			"palettes": {"sunset": {"colors": ["orange", "#ff0000"], "plain": true}},
+			"keys": {"pause": ["Space"]},
+			"overlays": {"stats": true, "minimap": false, "banner": "team", "font": "small", "timer": "countdown:10m"},
+			"behavior": {"lifecycle": true, "light": "fast", "current": "drift,streaks", "bubble-rate": 8}
+		}`, false},
+		{"no swarm", `{"swarm": 0}`, false},
+		{"not json", `swarm: 24`, true},
+		{"unknown setting", `{"swarn": 24}`, true},
+		{"negative swarm", `{"swarm": -1}`, true},
+		{"tick without unit", `{"tick": "100"}`, true},
+		{"tick as number", `{"tick": 100}`, true},
+		{"tick too short", `{"tick": "1ms"}`, true},
+		{"tick too long", `{"tick": "1h"}`, true},
+		{"unknown theme", `{"theme": "sunset"}`, true},
+		{"palette without colors", `{"palettes": {"sunset": {}}}`, true},
+		{"unknown color", `{"palettes": {"sunset": {"colors": ["sunny"]}}}`, true},
+		{"unknown species", `{"species": {"nemo": 1}}`, true},
+		{"negative weight", `{"species": {"` + fish + `": -1}}`, true},
+		{"unknown action", `{"keys": {"dance": ["z"]}}`, true},
+		{"key bound twice", `{"keys": {"pause": ["r"]}}`, true},
+		{"unknown font", `{"overlays": {"font": "comic"}}`, true},
+		{"unknown timer", `{"overlays": {"timer": "alarm"}}`, true},
+		{"countdown without duration", `{"overlays": {"timer": "countdown"}}`, true},
+		{"unknown light", `{"behavior": {"light": "bright"}}`, true},
+		{"unknown current", `{"behavior": {"current": "tide"}}`, true},
+		{"no bubbles", `{"behavior": {"bubble-rate": 0}}`, true},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.data))
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error = %v, want error %t", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	file, err := Parse([]byte(`{"swarm": 24, "tick": "100ms", "theme": "ocean", "overlays": {"minimap": false, "banner": "team", "timer": "clock"}, "behavior": {"light": "fast"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	swarm := 5
+	flags := &Config{Swarm: &swarm, Overlays: Overlays{Timer: "stopwatch"}, Behavior: Behavior{Current: "drift"}}
+	c := Default().Merge(file).Merge(flags)
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"the flags win over the file", *c.Swarm, 5},
+		{"the file wins over the defaults", time.Duration(*c.Tick), 100 * time.Millisecond},
+		{"the file sets the theme", c.Theme, "ocean"},
+		{"the file turns off a default", *c.Overlays.Minimap, false},
+		{"the defaults are kept", *c.Overlays.Stats, false},
+		{"the file sets the banner", c.Overlays.Banner, "team"},
+		{"the defaults keep the font", c.Overlays.Font, ""},
+		{"the flags set the timer", c.Overlays.Timer, "stopwatch"},
+		{"the file sets the light", c.Behavior.Light, "fast"},
+		{"the flags set the current", c.Behavior.Current, "drift"},
+		{"the defaults keep the bubble rate", *c.Behavior.BubbleRate, *Default().Behavior.BubbleRate},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("merged config is invalid: %v", err)
+	}
+	if *file.Swarm != 24 {
+		t.Errorf("merge changed the config it was merged from")
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Config
+		err  bool
+	}{
+		{"no theme", Config{}, false},
+		{"built in", Config{Theme: "mono"}, false},
+		{"palette", Config{Theme: "sunset", Palettes: map[string]Palette{"sunset": {Colors: []string{"red"}}}}, false},
+		{"palette before built in", Config{Theme: "mono", Palettes: map[string]Palette{"mono": {Colors: []string{"nope"}}}}, true},
+		{"unknown", Config{Theme: "sunset"}, true},
+	}
+	for _, tt := range tests {
+		if _, err := tt.c.LookupTheme(); (err != nil) != tt.err {
+			t.Errorf("%s: error = %v, want error %t", tt.name, err, tt.err)
+		}
+	}
+}
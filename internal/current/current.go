@@ -0,0 +1,178 @@
+package current
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lukasjoc/nemo/internal"
+)
+
+// A Flow is a part of the water current. It computes the velocity at a
+// position relative to the size of the water, where both x and y are in
+// the range of 0 to 1, at the given tick.
+type Flow func(x float64, y float64, t int) (float64, float64)
+
+// Uniform drifts everything into the same direction.
+func Uniform(dx float64, dy float64) Flow {
+	return func(float64, float64, int) (float64, float64) { return dx, dy }
+}
+
+// Vortex swirls everything around a center within the radius. The
+// strength is the velocity at the center, which fades out towards the edge.
+func Vortex(cx float64, cy float64, radius float64, strength float64) Flow {
+	return func(x float64, y float64, t int) (float64, float64) {
+		dx, dy := x-cx, y-cy
+		d := math.Hypot(dx, dy)
+		if d == 0 || d > radius {
+			return 0, 0
+		}
+		f := strength * (1 - d/radius) / d
+		// the terminal cells are about twice as high as wide
+		return -dy * f * 2, dx * f / 2
+	}
+}
+
+// Surge periodically pushes everything into the same direction, with a
+// calm in between the surges.
+func Surge(dx float64, dy float64, period int) Flow {
+	return func(x float64, y float64, t int) (float64, float64) {
+		f := math.Max(0, math.Sin(2*math.Pi*float64(t)/float64(period)))
+		return dx * f, dy * f
+	}
+}
+
+// Push pushes everything within the radius around a center, fading out
+// towards the edge.
+func Push(cx float64, cy float64, radius float64, dx float64, dy float64) Flow {
+	return func(x float64, y float64, t int) (float64, float64) {
+		d := math.Hypot(x-cx, y-cy)
+		if d > radius {
+			return 0, 0
+		}
+		f := 1 - d/radius
+		return dx * f, dy * f
+	}
+}
+
+// A gust is a flow that only lasts until a tick.
+type gust struct {
+	flow  Flow
+	until int
+}
+
+type Field struct {
+	Flows []Flow
+	// Shows faint particle streaks that follow the current.
+	Streaks bool
+	tick    int
+	gusts   []gust
+}
+
+func New(flows ...Flow) *Field { return &Field{Flows: flows} }
+
+// Parse creates a field from a comma separated list of flow names. The
+// special name `streaks` turns on the particle streaks.
+func Parse(spec string) (*Field, error) {
+	f := New()
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "drift":
+			f.Flows = append(f.Flows, Uniform(0.3, 0))
+		case "vortex":
+			f.Flows = append(f.Flows, Vortex(0.5, 0.5, 0.4, 0.6))
+		case "surge":
+			f.Flows = append(f.Flows, Surge(-0.8, 0.1, 200))
+		case "streaks":
+			f.Streaks = true
+		default:
+			return nil, fmt.Errorf("unknown current `%s`", name)
+		}
+	}
+	return f, nil
+}
+
+func (f *Field) Update() {
+	f.tick++
+	gusts := f.gusts[:0]
+	for _, g := range f.gusts {
+		if g.until > f.tick {
+			gusts = append(gusts, g)
+		}
+	}
+	f.gusts = gusts
+}
+
+// Gust adds a flow to the field for the given amount of ticks.
+func (f *Field) Gust(flow Flow, ticks int) {
+	f.gusts = append(f.gusts, gust{flow: flow, until: f.tick + ticks})
+}
+
+// At computes the velocity of the current at a position in the water.
+func (f *Field) At(x int, y int, w int, h int) (float64, float64) {
+	if len(f.Flows)+len(f.gusts) == 0 || w <= 0 || h <= 0 {
+		return 0, 0
+	}
+	rx, ry := float64(x)/float64(w), float64(y)/float64(h)
+	var dx, dy float64
+	for _, flow := range f.Flows {
+		fx, fy := flow(rx, ry, f.tick)
+		dx += fx
+		dy += fy
+	}
+	for _, g := range f.gusts {
+		fx, fy := g.flow(rx, ry, f.tick)
+		dx += fx
+		dy += fy
+	}
+	return dx, dy
+}
+
+// The amount of ticks a particle follows the current before it respawns.
+const particleLifetime = 40
+
+type Particle struct {
+	X   float64
+	Y   float64
+	DX  float64
+	DY  float64
+	age int
+}
+
+// StepParticles moves the particles along the current and respawns the
+// ones that are too old or left the water.
+func (f *Field) StepParticles(ps []Particle, w int, h int) {
+	for i := range ps {
+		p := &ps[i]
+		p.age++
+		if p.age > particleLifetime || p.X < 0 || p.Y < 0 ||
+			p.X >= float64(w) || p.Y >= float64(h) {
+			*p = Particle{
+				X:   float64(internal.IntRand(w)),
+				Y:   float64(internal.IntRand(h)),
+				age: internal.IntRand(particleLifetime),
+			}
+		}
+		p.DX, p.DY = f.At(int(p.X), int(p.Y), w, h)
+		p.X += p.DX
+		p.Y += p.DY
+	}
+}
+
+// Rune is the character that shows the direction of the particle.
+func (p Particle) Rune() rune {
+	if math.Abs(p.DX) < 0.05 && math.Abs(p.DY) < 0.05 {
+		return '.'
+	}
+	a := math.Atan2(p.DY, p.DX) / math.Pi * 4
+	switch int(math.Round(a)) {
+	case 0, 4, -4:
+		return '-'
+	case 1, -3:
+		return '\\'
+	case -1, 3:
+		return '/'
+	}
+	return '|'
+}
@@ -0,0 +1,34 @@
+package current
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		flows   int
+		streaks bool
+		err     bool
+	}{
+		{"", 0, false, false},
+		{"drift", 1, false, false},
+		{"drift, vortex,surge", 3, false, false},
+		{"streaks", 0, true, false},
+		{"vortex,streaks", 1, true, false},
+		{"drift,,", 1, false, false},
+		{"tide", 0, false, true},
+		{"drift,Vortex", 0, false, true},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.spec)
+		if (err != nil) != tt.err {
+			t.Errorf("Parse(%q) error = %v, want error %t", tt.spec, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(f.Flows) != tt.flows || f.Streaks != tt.streaks {
+			t.Errorf("Parse(%q) = %d flows, streaks %t, want %d flows, streaks %t", tt.spec, len(f.Flows), f.Streaks, tt.flows, tt.streaks)
+		}
+	}
+}
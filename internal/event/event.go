@@ -0,0 +1,159 @@
+package event
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/layer"
+)
+
+type Kind int
+
+const (
+	KindSpawn Kind = iota
+	KindDespawn
+	KindEat
+	KindCollide
+	KindResize
+	KindPause
+	KindKey
+	KindAlarm
+	KindFrame
+)
+
+// An Event is something that happened in the tank. Every kind of event has
+// its own payload type.
+type Event interface {
+	Kind() Kind
+}
+
+// Spawn is published when a layer enters the tank.
+type Spawn struct{ Layer *layer.Layer }
+
+// Despawn is published when a layer leaves the tank.
+type Despawn struct{ Layer *layer.Layer }
+
+// Eat is published when a layer eats another one.
+type Eat struct {
+	Eater *layer.Layer
+	Food  *layer.Layer
+}
+
+// Collide is published when a layer is about to run into another one.
+type Collide struct {
+	Layer *layer.Layer
+	Other *layer.Layer
+}
+
+// Resize is published when the size of the tank changes.
+type Resize struct {
+	W int
+	H int
+}
+
+// Pause is published when the renderer is stopped or started again.
+type Pause struct{ Paused bool }
+
+// Key is published for every key that is pressed.
+type Key struct{ Event *tcell.EventKey }
+
+// Alarm is published when a countdown runs out.
+type Alarm struct{}
+
+// Frame is published after every frame that was drawn to the screen, with a
+// copy of what is on it, row by row. The screen itself might already show
+// the next frame by the time it is handled.
+type Frame struct {
+	Time  time.Time
+	Cells [][]Cell
+}
+
+// A Cell is what a position on the screen shows.
+type Cell struct {
+	Rune  rune
+	Style tcell.Style
+}
+
+func (Spawn) Kind() Kind   { return KindSpawn }
+func (Despawn) Kind() Kind { return KindDespawn }
+func (Eat) Kind() Kind     { return KindEat }
+func (Collide) Kind() Kind { return KindCollide }
+func (Resize) Kind() Kind  { return KindResize }
+func (Pause) Kind() Kind   { return KindPause }
+func (Key) Kind() Kind     { return KindKey }
+func (Alarm) Kind() Kind   { return KindAlarm }
+func (Frame) Kind() Kind   { return KindFrame }
+
+type handler struct {
+	id int
+	fn func(Event)
+}
+
+// A Bus delivers the published events to all the subscribers of their
+// kind. The handlers are called synchronously on the goroutine that
+// publishes the event, in the order they subscribed.
+// NOTE: the renderer publishes the events of a frame after it was drawn
+// without holding any locks, so handlers are free to call back into the
+// renderer. Events that are published from elsewhere, like the pause events
+// of Stop and Start, arent ordered with the ones of the frames, and their
+// handlers might run at the same time.
+type Bus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[Kind][]handler
+	all      []handler
+}
+
+func NewBus() *Bus { return &Bus{handlers: map[Kind][]handler{}} }
+
+// Subscribe calls the handler for every published event of the type E. It
+// returns a function to unsubscribe the handler again.
+func Subscribe[E Event](b *Bus, fn func(E)) func() {
+	var zero E
+	kind := zero.Kind()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[kind] = append(b.handlers[kind], handler{id, func(e Event) { fn(e.(E)) }})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.handlers[kind] = without(b.handlers[kind], id)
+	}
+}
+
+// SubscribeAll calls the handler for every published event of any kind.
+func (b *Bus) SubscribeAll(fn func(Event)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.all = append(b.all, handler{id, fn})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.all = without(b.all, id)
+	}
+}
+
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	handlers := slices.Concat(b.handlers[e.Kind()], b.all)
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		h.fn(e)
+	}
+}
+
+func without(handlers []handler, id int) []handler {
+	kept := []handler{}
+	for _, h := range handlers {
+		if h.id != id {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,186 @@
+//go:build unix
+
+package idle
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// The time after the screensaver exited in which input is dropped, as the
+// terminal might still send the rest of the key or the mouse events that
+// ended it.
+const quietTime = 250 * time.Millisecond
+
+// gate is a writer that can be closed for a while.
+type gate struct {
+	sync.Mutex
+	w io.Writer
+}
+
+func (g *gate) Write(p []byte) (int, error) {
+	g.Lock()
+	defer g.Unlock()
+	return g.w.Write(p)
+}
+
+// The time the input is waited for at once, so the screensaver can take
+// over the terminal in between.
+const pollTime = 50 * time.Millisecond
+
+// Run runs the shell in a pseudo terminal and passes everything between it
+// and the terminal through. Once the shell waited at its prompt without any
+// input for the duration, the screensaver runs until the next input, which
+// it swallows. The output of the shell is held back while the screensaver
+// runs. Run returns once the shell exited.
+func Run(shell *exec.Cmd, after time.Duration, screensaver func() *exec.Cmd) error {
+	ptmx, err := pty.Start(shell)
+	if err != nil {
+		return err
+	}
+	defer ptmx.Close()
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		shell.Process.Kill()
+		shell.Wait()
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+	go func() {
+		for range resize {
+			pty.InheritSize(os.Stdin, ptmx)
+		}
+	}()
+	resize <- syscall.SIGWINCH
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	// the input is only read while holding tty, which the screensaver
+	// holds while it runs, so it gets all the input
+	var tty sync.Mutex
+	in := make(chan []byte)
+	go func() {
+		for {
+			buf := make([]byte, 4096)
+			n, err := 0, error(nil)
+			tty.Lock()
+			if ready(fd) {
+				n, err = os.Stdin.Read(buf)
+			}
+			tty.Unlock()
+			if err != nil {
+				close(in)
+				return
+			}
+			if n > 0 {
+				in <- buf[:n]
+			}
+		}
+	}()
+	out := &gate{w: os.Stdout}
+	done := make(chan error, 1)
+	go func() {
+		io.Copy(out, ptmx)
+		done <- shell.Wait()
+	}()
+
+	timer := time.NewTimer(after)
+	defer timer.Stop()
+	// the screensaver while it runs and when it exits
+	var saver *exec.Cmd
+	exited := make(chan error, 1)
+	quiet := time.Time{}
+	stop := func() {
+		if saver != nil {
+			saver.Process.Signal(syscall.SIGTERM)
+		}
+	}
+	for {
+		select {
+		case data, ok := <-in:
+			switch {
+			case !ok:
+				in = nil
+			case time.Now().Before(quiet):
+			default:
+				ptmx.Write(data)
+				reset(timer, after)
+			}
+		case <-timer.C:
+			if !atPrompt(ptmx, shell) {
+				timer.Reset(after)
+				continue
+			}
+			tty.Lock()
+			out.Lock()
+			saver = screensaver()
+			saver.Stdin, saver.Stdout, saver.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := saver.Start(); err != nil {
+				out.Unlock()
+				tty.Unlock()
+				saver = nil
+				continue
+			}
+			go func(c *exec.Cmd) { exited <- c.Wait() }(saver)
+		case <-exited:
+			saver = nil
+			out.Unlock()
+			tty.Unlock()
+			quiet = time.Now().Add(quietTime)
+			reset(timer, after)
+		case <-sigs:
+			stop()
+			shell.Process.Signal(syscall.SIGHUP)
+		case err := <-done:
+			if saver != nil {
+				stop()
+				<-exited
+				out.Unlock()
+				tty.Unlock()
+			}
+			return err
+		}
+	}
+}
+
+// reset restarts the timer, without a tick of before that wasnt received.
+func reset(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+// ready waits a little for input on the file descriptor and reports if
+// there is some.
+func ready(fd int) bool {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(pollTime/time.Millisecond))
+	return err == nil && n > 0
+}
+
+// atPrompt reports if the shell itself is in the foreground of its
+// terminal, which means it doesnt run a command.
+func atPrompt(ptmx *os.File, shell *exec.Cmd) bool {
+	pgrp, err := unix.IoctlGetInt(int(ptmx.Fd()), unix.TIOCGPGRP)
+	return err == nil && pgrp == shell.Process.Pid
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Run needs a pseudo terminal, which only exists on unix.
+func Run(shell *exec.Cmd, after time.Duration, screensaver func() *exec.Cmd) error {
+	return fmt.Errorf("idle is not supported on this platform")
+}
@@ -0,0 +1,205 @@
+package keymap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// An Action is something the user can do with a key.
+type Action string
+
+const (
+	Quit          Action = "quit"
+	Pause         Action = "pause"
+	Step          Action = "step"
+	Restart       Action = "restart"
+	SpeedUp       Action = "speed-up"
+	SpeedDown     Action = "speed-down"
+	AddFish       Action = "add-fish"
+	RemoveFish    Action = "remove-fish"
+	AddSchool     Action = "add-school"
+	RemoveSpecies Action = "remove-species"
+	Stats         Action = "stats"
+	Screenshot    Action = "screenshot"
+	Help          Action = "help"
+	Inspect       Action = "inspect"
+	Next          Action = "next"
+	Freeze        Action = "freeze"
+	Remove        Action = "remove"
+	Follow        Action = "follow"
+	Minimap       Action = "minimap"
+	Timer         Action = "timer"
+	Up            Action = "up"
+	Down          Action = "down"
+	Left          Action = "left"
+	Right         Action = "right"
+)
+
+// All the actions in the order they are listed in the help.
+var Actions = []Action{
+	Pause, Step, Restart, SpeedUp, SpeedDown, AddFish, RemoveFish,
+	AddSchool, RemoveSpecies, Stats, Screenshot, Help, Inspect, Next,
+	Freeze, Remove, Follow, Minimap, Timer, Up, Down, Left, Right, Quit,
+}
+
+// A Key is either a special key or a rune.
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+}
+
+func (k Key) String() string {
+	if k.Key == tcell.KeyRune {
+		if k.Rune == ' ' {
+			return "Space"
+		}
+		return string(k.Rune)
+	}
+	return tcell.KeyNames[k.Key]
+}
+
+// ParseKey parses a single rune like `p` or the name of a special key like
+// `Esc`, `Up` or `Ctrl-C`.
+func ParseKey(name string) (Key, error) {
+	if name == "Space" {
+		return Key{tcell.KeyRune, ' '}, nil
+	}
+	if r := []rune(name); len(r) == 1 {
+		return Key{tcell.KeyRune, r[0]}, nil
+	}
+	for k, n := range tcell.KeyNames {
+		if strings.EqualFold(n, name) {
+			return Key{Key: k}, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown key `%s`", name)
+}
+
+type Keymap map[Key]Action
+
+func Default() Keymap {
+	return Keymap{
+		{Key: tcell.KeyEscape}: Quit,
+		{Key: tcell.KeyCtrlC}:  Quit,
+		{tcell.KeyRune, 'p'}:   Pause,
+		{tcell.KeyRune, '.'}:   Step,
+		{tcell.KeyRune, 'r'}:   Restart,
+		{tcell.KeyRune, '+'}:   SpeedUp,
+		{tcell.KeyRune, '-'}:   SpeedDown,
+		{tcell.KeyRune, 'a'}:   AddFish,
+		{tcell.KeyRune, 'd'}:   RemoveFish,
+		{tcell.KeyRune, 'A'}:   AddSchool,
+		{tcell.KeyRune, 'D'}:   RemoveSpecies,
+		{tcell.KeyRune, 's'}:   Stats,
+		{tcell.KeyRune, 'c'}:   Screenshot,
+		{tcell.KeyRune, '?'}:   Help,
+		{tcell.KeyRune, 'i'}:   Inspect,
+		{Key: tcell.KeyTab}:    Next,
+		{tcell.KeyRune, 'f'}:   Freeze,
+		{tcell.KeyRune, 'x'}:   Remove,
+		{tcell.KeyRune, 'F'}:   Follow,
+		{tcell.KeyRune, 'm'}:   Minimap,
+		{tcell.KeyRune, 't'}:   Timer,
+		{Key: tcell.KeyUp}:     Up,
+		{Key: tcell.KeyDown}:   Down,
+		{Key: tcell.KeyLeft}:   Left,
+		{Key: tcell.KeyRight}:  Right,
+	}
+}
+
+// Path is where the keymap is loaded from by default.
+func Path() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "nemo", "keys.json")
+}
+
+// Parse parses a JSON object that maps actions to a list of keys, like
+// `{"pause": ["p", "Space"]}`. The keys of every action in it replace the
+// default keys of that action.
+func Parse(data []byte) (Keymap, error) {
+	bindings := map[Action][]string{}
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return nil, err
+	}
+	return Default().With(bindings)
+}
+
+// With replaces the keys of the given actions.
+func (k Keymap) With(bindings map[Action][]string) (Keymap, error) {
+	keys := Keymap{}
+	for key, action := range k {
+		if _, ok := bindings[action]; !ok {
+			keys[key] = action
+		}
+	}
+	for action, names := range bindings {
+		if !slices.Contains(Actions, action) {
+			return nil, fmt.Errorf("unknown action `%s`", action)
+		}
+		for _, name := range names {
+			key, err := ParseKey(name)
+			if err != nil {
+				return nil, fmt.Errorf("action `%s`: %v", action, err)
+			}
+			if other, ok := keys[key]; ok && other != action {
+				return nil, fmt.Errorf("action `%s`: key `%s` is already bound to `%s`", action, name, other)
+			}
+			keys[key] = action
+		}
+	}
+	return keys, nil
+}
+
+// Load loads the keymap from a file. A file that doesnt exist leaves the
+// default keymap.
+func Load(path string) (Keymap, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || path == "" {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	keys, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return keys, nil
+}
+
+func (k Keymap) Lookup(ev *tcell.EventKey) (Action, bool) {
+	key := Key{Key: ev.Key()}
+	if ev.Key() == tcell.KeyRune {
+		key.Rune = ev.Rune()
+	}
+	action, ok := k[key]
+	return action, ok
+}
+
+// Help lists every action with the keys bound to it.
+func (k Keymap) Help() []string {
+	lines := []string{}
+	for _, action := range Actions {
+		names := []string{}
+		for key, a := range k {
+			if a == action {
+				names = append(names, key.String())
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		slices.Sort(names)
+		lines = append(lines, fmt.Sprintf("%-12s %s", action, strings.Join(names, ", ")))
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+package keymap
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want Key
+		err  bool
+	}{
+		{"p", Key{tcell.KeyRune, 'p'}, false},
+		{"P", Key{tcell.KeyRune, 'P'}, false},
+		{"ä", Key{tcell.KeyRune, 'ä'}, false},
+		{"Space", Key{tcell.KeyRune, ' '}, false},
+		{"Esc", Key{Key: tcell.KeyEscape}, false},
+		{"ctrl-c", Key{Key: tcell.KeyCtrlC}, false},
+		{"Up", Key{Key: tcell.KeyUp}, false},
+		{"", Key{}, true},
+		{"nope", Key{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.name)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseKey(%q) error = %v, want error %t", tt.name, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseKey(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[Action][]string
+		want     map[Key]Action
+		gone     []Key
+		err      bool
+	}{
+		{
+			name:     "nothing",
+			bindings: nil,
+			want:     map[Key]Action{{tcell.KeyRune, 'p'}: Pause},
+		},
+		{
+			name:     "replaces the default keys",
+			bindings: map[Action][]string{Pause: {"Space", "P"}},
+			want:     map[Key]Action{{tcell.KeyRune, ' '}: Pause, {tcell.KeyRune, 'P'}: Pause, {tcell.KeyRune, 'r'}: Restart},
+			gone:     []Key{{tcell.KeyRune, 'p'}},
+		},
+		{
+			name:     "no keys unbinds",
+			bindings: map[Action][]string{Step: {}},
+			gone:     []Key{{tcell.KeyRune, '.'}},
+		},
+		{
+			name:     "takes a key of an action that is rebound",
+			bindings: map[Action][]string{Pause: {"r"}, Restart: {"R"}},
+			want:     map[Key]Action{{tcell.KeyRune, 'r'}: Pause, {tcell.KeyRune, 'R'}: Restart},
+		},
+		{
+			name:     "key of another action",
+			bindings: map[Action][]string{Pause: {"r"}},
+			err:      true,
+		},
+		{
+			name:     "unknown action",
+			bindings: map[Action][]string{"dance": {"z"}},
+			err:      true,
+		},
+		{
+			name:     "unknown key",
+			bindings: map[Action][]string{Pause: {"nope"}},
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := Default().With(tt.bindings)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error = %v, want error %t", tt.name, err, tt.err)
+			continue
+		}
+		for key, action := range tt.want {
+			if got[key] != action {
+				t.Errorf("%s: key %v = %q, want %q", tt.name, key, got[key], action)
+			}
+		}
+		for _, key := range tt.gone {
+			if action, ok := got[key]; ok {
+				t.Errorf("%s: key %v is still bound to %q", tt.name, key, action)
+			}
+		}
+	}
+}
@@ -2,89 +2,270 @@ package layer
 
 import (
 	"fmt"
-	"unicode"
+	"strings"
 
 	"github.com/gdamore/tcell"
 	"github.com/lukasjoc/nemo/internal"
 	"github.com/lukasjoc/nemo/internal/assets"
 )
 
-type Layer struct {
-	X          int
-	Y          int
-	Velo       int
-	hidden     bool
-	style      tcell.Style
+// The amount of depth planes layers can be in. The nearest plane is 0.
+const DepthPlanes = 3
+
+// Position is where a layer is in the water.
+type Position struct {
+	X int
+	Y int
+	// The depth plane of the layer. Layers further away are drawn dimmer,
+	// move slower and are occluded by nearer ones.
+	Z int
+	// the position the layer started at, used by movement models that
+	// oscillate.
+	originX int
+	originY int
+	// the part of the water current that didnt add up to a whole cell yet.
+	driftX float64
+	driftY float64
+}
+
+// Velocity moves a layer according to its movement model.
+type Velocity struct {
+	Velo int
+	// The movement model that computes the next position after each draw.
+	Movement Movement
+	// the amount of draws since the layer was created, used by movement
+	// models that depend on time.
+	tick int
+	// skips the next movement to let the layer slow down.
+	slowed bool
+	// a frozen layer doesnt move, think or age until it is thawed.
+	frozen bool
+}
+
+// Sprite is the asset a layer is drawn with.
+type Sprite struct {
 	Asset      assets.Asset
 	AssetIndex int
-	// NOTE: that the drawFunc doesnt actual.Y update the screen
-	// it just computes the next l.Yer. Its up to the renderer to sync
-	// the changes to the screen. This effectively allows for double buffering.
-	Draw func(l *Layer, sc tcell.Screen)
+	// Glowing sprites shine in their own light.
+	Glow  bool
+	style tcell.Style
+	// the colors of the theme the style is picked from, nil if it never
+	// changes.
+	palette *[]tcell.Style
+	// computes the style of every rune of the asset.
+	mask func(l *Layer, r rune) tcell.Style
+}
+
+// Animation picks the source of the sprite to draw, which defaults to the
+// asset index.
+type Animation struct {
+	Frame func(l *Layer) int
+}
+
+// Behavior are the decisions a layer makes before it moves.
+type Behavior struct {
+	Think func(l *Layer, w int, h int)
+	// the remaining draws of the current turn and the amount of turns the
+	// layer is still allowed to make.
+	turning int
+	turns   int
+	// the remaining steps of the current burst and the remaining ticks of
+	// the current pause for bottom walkers.
+	steps int
+	pause int
+}
+
+// Identity is what makes a creature unique.
+type Identity struct {
+	Name string
+}
+
+// Lifetime decides when a layer leaves the tank.
+type Lifetime struct {
+	// Reports if the layer left the screen with the given size.
+	Exit   func(l *Layer, w int, h int) bool
+	hidden bool
+	// the life of the layer in the lifecycle simulation.
+	life *life
+}
+
+// A Layer is an entity in the tank. It is composed of components, any of
+// which might be missing. The systems only act on layers that have all the
+// components they need.
+type Layer struct {
+	*Position
+	*Velocity
+	*Sprite
+	*Animation
+	*Behavior
+	*Lifetime
+	*Identity
 }
 
+func (l *Layer) Hidden() bool { return l.Lifetime != nil && l.hidden }
+
 func FindHidden(layers []*Layer) []int {
 	idx := []int{}
 	for i, l := range layers {
-		if l != nil && l.hidden {
+		if l != nil && l.Hidden() {
 			idx = append(idx, i)
 		}
 	}
 	return idx
 }
 
-func (l Layer) String() string {
-	return fmt.Sprintf("x:%4d y:%4d velo:%4d hidden:%6t group:%6s",
-		l.X, l.Y, l.Velo, l.hidden, l.Asset.Group)
-}
-
-func fishDrawFunc(l *Layer, sc tcell.Screen) {
-	drawW, _ := sc.Size()
-	initialX := l.X
-	initialY := l.Y
-	ty := initialY
-	for _, tile := range l.Asset.Sources[l.AssetIndex] {
-		tlen := len(tile)
-		if tlen == 0 {
-			continue
-		}
-		tx := initialX
-		for _, r := range tile {
-			// clear any garbage from the previous draw
-			if l.Velo > 0 {
-				for i := initialX - (l.Velo) - 1; i < initialX; i++ {
-					sc.SetContent(i, ty, ' ', nil, tcell.StyleDefault)
-				}
-			}
-			if l.Velo < 0 {
-				for i := (initialX + tlen); i < (initialX + tlen + -l.Velo); i++ {
-					sc.SetContent(i, ty, ' ', nil, tcell.StyleDefault)
-				}
-			}
-			// draw space in default color to not leave any (invisible) trails
-			if unicode.IsSpace(r) {
-				sc.SetContent(tx, ty, r, nil, tcell.StyleDefault)
-			} else {
-				sc.SetContent(tx, ty, r, nil, bodypartColorMask(r))
-			}
-			tx++
+// Visible returns all the layers that are not nil and not hidden, in a new
+// slice.
+func Visible(layers []*Layer) []*Layer {
+	visible := []*Layer{}
+	for _, l := range layers {
+		if l != nil && !l.Hidden() {
+			visible = append(visible, l)
 		}
-		ty++
 	}
-	if l.Velo > 0 && l.X > drawW+l.Asset.Width ||
-		l.Velo < 0 && l.X < -l.Asset.Width {
-		(*l).hidden = true
+	return visible
+}
+
+func (l Layer) String() string {
+	var sb strings.Builder
+	if l.Position != nil {
+		fmt.Fprintf(&sb, "x:%4d y:%4d z:%2d ", l.X, l.Y, l.Z)
+	}
+	if l.Velocity != nil {
+		fmt.Fprintf(&sb, "velo:%4d ", l.Velo)
+	}
+	fmt.Fprintf(&sb, "hidden:%6t", l.Hidden())
+	if l.Sprite != nil {
+		fmt.Fprintf(&sb, " group:%6s", l.Asset.Group)
+	}
+	return sb.String()
+}
+
+// Inspect describes the live state of all the components of the layer, one
+// line per property.
+func (l *Layer) Inspect() []string {
+	lines := []string{}
+	add := func(format string, a ...any) { lines = append(lines, fmt.Sprintf(format, a...)) }
+	if l.Identity != nil {
+		add("Name: %s", l.Name)
+	}
+	if l.Sprite != nil {
+		add("Species: %s", l.Species())
+		add("Group: %s", l.Asset.Group)
+		add("Frame: %d/%d", l.frame(), len(l.Asset.Sources))
+	}
+	if l.Position != nil {
+		add("Position: %d,%d", l.X, l.Y)
+		add("Depth: %d", l.Z)
+	}
+	if l.Velocity != nil {
+		add("Velocity: %d", l.Velo)
+		add("Tick: %d", l.tick)
+		add("Frozen: %t", l.frozen)
+	}
+	if l.Behavior != nil {
+		add("Turning: %d", l.turning)
+		add("Turns: %d", l.turns)
+	}
+	if l.Mortal() {
+		add("Age: %d/%d", l.life.age, l.life.lifespan)
+		add("Adult: %t", l.Adult())
+	}
+	return lines
+}
+
+// Restyle picks a new style from the current theme.
+func (l *Layer) Restyle() {
+	if l.Sprite != nil && l.palette != nil {
+		l.style = internal.Choose(*l.palette...)
+	}
+}
+
+func bodypartMask(l *Layer, r rune) tcell.Style {
+	if plain {
+		return l.style
 	}
-	(*l).X += l.Velo
+	return bodypartColorMask(r)
+}
+
+func solidMask(l *Layer, r rune) tcell.Style { return l.style }
+
+func sandMask(l *Layer, r rune) tcell.Style { return sandColorMask(r) }
+
+// exitAhead reports layers that left the screen in their direction.
+func exitAhead(l *Layer, w int, h int) bool {
+	return l.Velo > 0 && l.X > w+l.Asset.Width ||
+		l.Velo < 0 && l.X < -l.Asset.Width
+}
+
+// exitTop reports layers that rose above the screen.
+func exitTop(l *Layer, w int, h int) bool { return l.Y < -l.Asset.Height }
+
+// thinkSwim lets fish that are about to swim out of the screen turn
+// around, every now and then they also turn around just because.
+func thinkSwim(l *Layer, w int, h int) {
+	if onScreen(l, w) && (l.X+l.Velo <= 0 ||
+		l.X+l.Asset.Width+l.Velo >= w || internal.IntRand(200) == 0) {
+		l.Turn()
+	}
+}
+
+// frameGrow lets bubbles grow the closer they get to the surface.
+func frameGrow(l *Layer) int {
+	if l.originY <= 0 {
+		return l.AssetIndex
+	}
+	stages := len(l.Asset.Sources)
+	return clamp((l.originY-l.Y)*stages/l.originY, 0, stages-1)
+}
+
+var names = []string{
+	"Nemo", "Dory", "Marlin", "Gill", "Bubbles", "Peach", "Gurgle", "Jacques",
+	"Deb", "Bloat", "Squirt", "Crush", "Coral", "Bruce", "Anchor", "Chum",
+	"Pearl", "Tad", "Sheldon", "Wanda", "Finn", "Goldie", "Splash", "Wally",
 }
 
+func newRandIdentity() *Identity { return &Identity{Name: internal.Choose(names...)} }
+
 func NewRandFish(w int, h int) *Layer {
-	asset := assets.Random("fish")
+	return newRandSwimmer(assets.Random("fish"), w, h)
+}
+
+// NewFish creates a fish of the species of the given asset that swims in
+// from a random side.
+func NewFish(asset assets.Asset, w int, h int) *Layer {
+	return newRandSwimmer(asset, w, h)
+}
+
+// NewRandGlower creates a bioluminescent creature that only comes out when
+// it is dark.
+func NewRandGlower(w int, h int) *Layer {
+	l := newRandSwimmer(assets.Random("glow"), w, h)
+	l.style = internal.Choose(Glows...)
+	l.palette = &Glows
+	l.mask = solidMask
+	l.Glow = true
+	return l
+}
+
+func newRandSwimmer(asset assets.Asset, w int, h int) *Layer {
 	l := Layer{
-		Velo:       internal.Choose(2, 1, 3),
-		style:      internal.Choose(Colors...),
-		Asset:      asset,
-		AssetIndex: internal.Choose(0, 1),
+		Position: &Position{Z: internal.IntRand(DepthPlanes)},
+		Velocity: &Velocity{
+			Velo:     internal.Choose(2, 1, 3),
+			Movement: movementFor(asset, moveStraight),
+		},
+		Sprite: &Sprite{
+			Asset:      asset,
+			AssetIndex: internal.Choose(0, 1),
+			style:      internal.Choose(Colors...),
+			palette:    &Colors,
+			mask:       bodypartMask,
+		},
+		Animation: &Animation{Frame: frameTurn},
+		Behavior:  &Behavior{Think: thinkSwim, turns: internal.IntRand(3)},
+		Lifetime:  &Lifetime{Exit: exitAhead},
+		Identity:  newRandIdentity(),
 	}
 	leftSide := l.AssetIndex == 0
 	if leftSide {
@@ -95,49 +276,162 @@ func NewRandFish(w int, h int) *Layer {
 		l.Y = internal.IntRand(h - asset.Height)
 		l.Velo *= -1
 	}
-	l.Draw = fishDrawFunc
+	l.originY = l.Y
 	return &l
 }
 
-func bubbleDrawFunc(l *Layer, sc tcell.Screen) {
-	(*l).Asset = assets.Random("bubble")
-	initialX := l.X
-	initialY := l.Y
-	ty := initialY
-	for _, tile := range l.Asset.Sources[l.AssetIndex] {
-		tlen := len(tile)
-		if tlen == 0 {
-			continue
-		}
-		tx := initialX
-		for _, r := range tile {
-			// TODO: dont rely on asset size implicitly
-			sc.SetContent(tx, ty-l.Velo, ' ', nil, tcell.StyleDefault)
-			if !unicode.IsSpace(r) {
-				sc.SetContent(tx, ty, r, nil, l.style)
-			} else {
-				sc.SetContent(tx, ty, r, nil, tcell.StyleDefault)
-			}
-			tx++
+func NewBubble(x int, y int) *Layer {
+	asset := assets.Random("bubble")
+	l := Layer{
+		Position: &Position{X: x, Y: y, originX: x, originY: y},
+		Velocity: &Velocity{
+			Velo:     -internal.Choose(1, 1, 2),
+			Movement: movementFor(asset, moveRise),
+			tick:     internal.IntRand(8),
+		},
+		Sprite: &Sprite{
+			Asset:   asset,
+			style:   internal.Choose(Blues...),
+			palette: &Blues,
+			mask:    solidMask,
+		},
+		Animation: &Animation{Frame: frameGrow},
+		Lifetime:  &Lifetime{Exit: exitTop},
+	}
+	return &l
+}
+
+// The amount of draws food lasts before it dissolves.
+const foodLifetime = 400
+
+// exitDissolved reports food that lay around for too long.
+func exitDissolved(l *Layer, w int, h int) bool { return l.tick > foodLifetime }
+
+// NewFood creates a flake of food that sinks down to the floor.
+func NewFood(x int, y int) *Layer {
+	asset := assets.Random("food")
+	l := Layer{
+		Position: &Position{X: x, Y: y, originX: x, originY: y},
+		Velocity: &Velocity{Velo: 1, Movement: movementFor(asset, moveSink)},
+		Sprite: &Sprite{
+			Asset: asset,
+			style: foodStyle,
+			mask:  solidMask,
+		},
+		Lifetime: &Lifetime{Exit: exitDissolved},
+	}
+	return &l
+}
+
+// Eat hides the food.
+func (l *Layer) Eat() { l.Remove() }
+
+// Remove hides the layer, so it is taken out of the tank like a layer that
+// left the screen. Layers that never leave get a lifetime for it.
+func (l *Layer) Remove() {
+	if l.Lifetime == nil {
+		l.Lifetime = &Lifetime{}
+	}
+	l.hidden = true
+}
+
+// The height of the sand/gravel floor band at the bottom of the screen.
+const FloorHeight = 2
+
+func NewFloor(w int, h int) *Layer {
+	tiles := make([]string, FloorHeight)
+	for i := range tiles {
+		tiles[i] = sand(i, w)
+	}
+	l := Layer{
+		Position: &Position{X: 0, Y: h - FloorHeight},
+		Sprite: &Sprite{
+			Asset: assets.Asset{Group: "floor", Sources: [][]string{tiles}, Width: w, Height: FloorHeight},
+			mask:  sandMask,
+		},
+	}
+	return &l
+}
+
+// sand creates w random cells of a row of the floor.
+func sand(row int, w int) string {
+	var sb strings.Builder
+	for j := 0; j < w; j++ {
+		if row == 0 {
+			sb.WriteRune(internal.Choose('_', '_', '_', '.', ',', '-', '~'))
+		} else {
+			sb.WriteRune(internal.Choose('.', '.', ':', ',', '\'', ';', 'o', 'O', '0'))
 		}
-		ty++
 	}
-	if l.Y < -l.Asset.Height {
-		(*l).hidden = true
+	return sb.String()
+}
+
+// ResizeFloor moves the floor to the bottom of a screen of the new size. It
+// keeps the sand it already has and only adds more if it got wider.
+func (l *Layer) ResizeFloor(w int, h int) {
+	tiles := l.Asset.Sources[0]
+	for i, tile := range tiles {
+		if len(tile) >= w {
+			tiles[i] = tile[:w]
+		} else {
+			tiles[i] = tile + sand(i, w-len(tile))
+		}
 	}
-	(*l).Y += l.Velo
+	l.Asset.Width = w
+	l.Y = h - FloorHeight
 }
 
-func NewRandBubble(w int, h int) *Layer {
-	asset := assets.Random("bubble")
+func NewRandWalker(w int, h int) *Layer {
+	asset := assets.Random("walker")
 	l := Layer{
-		Velo:       -internal.Choose(3, 2, 4, 5),
-		style:      internal.Choose(Blues...),
-		Asset:      asset,
-		X:          internal.IntRand(w),
-		Y:          internal.IntRand(h / 2),
-		AssetIndex: 0,
-	}
-	l.Draw = bubbleDrawFunc
+		Position: &Position{Y: h - FloorHeight - asset.Height},
+		Velocity: &Velocity{Velo: 1, Movement: movementFor(asset, moveWalk)},
+		Sprite: &Sprite{
+			Asset:      asset,
+			AssetIndex: internal.Choose(0, 1),
+			style:      internal.Choose(Colors...),
+			palette:    &Colors,
+			mask:       solidMask,
+		},
+		Behavior: &Behavior{steps: internal.IntRand(10) + 3},
+		Lifetime: &Lifetime{Exit: exitAhead},
+		Identity: newRandIdentity(),
+	}
+	if l.AssetIndex == 0 {
+		l.X = -asset.Width
+	} else {
+		l.X = w
+		l.Velo *= -1
+	}
+	l.originY = l.Y
 	return &l
 }
+
+type Rect struct {
+	X int
+	Y int
+	W int
+	H int
+}
+
+func (r Rect) Overlaps(o Rect) bool {
+	return r.X < o.X+o.W && o.X < r.X+r.W &&
+		r.Y < o.Y+o.H && o.Y < r.Y+r.H
+}
+
+func (r Rect) Contains(x int, y int) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+func (l *Layer) Bounds() Rect {
+	return Rect{X: l.X, Y: l.Y, W: l.Asset.Width, H: l.Asset.Height}
+}
+
+// Mouth is the position right in front of the layer in its facing direction.
+func (l *Layer) Mouth() (int, int) {
+	y := l.Y + l.Asset.Height/2
+	if l.Velo < 0 {
+		return l.X - 1, y
+	}
+	return l.X + l.Asset.Width, y
+}
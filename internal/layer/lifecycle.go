@@ -0,0 +1,112 @@
+package layer
+
+import (
+	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/assets"
+)
+
+// All the durations of the lifecycle are counted in draws.
+const (
+	// The age at which fry grow up to adults.
+	growAge = 600
+	// The shortest and longest lifespan of a fish.
+	minLifespan = 5000
+	maxLifespan = 9000
+	// The distance within which two adults of the same species can breed.
+	breedDistance = 12
+	// On average a pair of adults breeds every `breedRate` draws.
+	breedRate = 400
+)
+
+// The life of a layer within the lifecycle simulation. Layers that are not
+// part of the simulation have no life and live forever.
+type life struct {
+	age      int
+	lifespan int
+	adult    assets.Asset
+}
+
+func newLife(age int, adult assets.Asset) *life {
+	return &life{
+		age:      age,
+		lifespan: minLifespan + internal.IntRand(maxLifespan-minLifespan),
+		adult:    adult,
+	}
+}
+
+// MakeMortal makes the layer part of the lifecycle simulation as a grown up
+// adult of some random age. Mortal fish dont leave the screen anymore but
+// turn around at the edges until they die.
+func (l *Layer) MakeMortal() {
+	l.life = newLife(0, l.Asset)
+	l.life.age = growAge + internal.IntRand(l.life.lifespan-growAge)
+	l.turns = l.life.lifespan
+}
+
+func (l *Layer) Mortal() bool { return l.Lifetime != nil && l.life != nil }
+
+func (l *Layer) Adult() bool { return !l.Mortal() || l.life.age >= growAge }
+
+// Species is the species of the layer, even if it is not grown up yet.
+func (l *Layer) Species() string {
+	if l.Mortal() {
+		return l.life.adult.Species
+	}
+	return l.Asset.Species
+}
+
+// Live ages the layer by one draw. Fry grow up to adults within the given
+// water height and old fish die.
+func (l *Layer) Live(h int) {
+	if !l.Mortal() {
+		return
+	}
+	l.life.age++
+	if l.life.age == growAge {
+		l.Asset = l.life.adult
+		l.Y = clamp(l.Y, 0, h-l.Asset.Height)
+		l.originY = l.Y
+	}
+	if l.life.age >= l.life.lifespan {
+		l.hidden = true
+	}
+}
+
+// Breed lets two adults of the same species that are close to each other
+// produce offspring every now and then. It returns nil if they dont.
+func (l *Layer) Breed(o *Layer) *Layer {
+	if !l.Mortal() || !o.Mortal() || !l.Adult() || !o.Adult() ||
+		l.Species() != o.Species() {
+		return nil
+	}
+	if internal.Abs(l.X-o.X) > breedDistance || internal.Abs(l.Y-o.Y) > breedDistance/2 ||
+		internal.IntRand(breedRate) != 0 {
+		return nil
+	}
+	return NewFry(l)
+}
+
+// NewFry creates the young of a parent right in front of it.
+func NewFry(parent *Layer) *Layer {
+	x, y := parent.Mouth()
+	position := *parent.Position
+	position.X, position.Y, position.originY = x, y, y
+	velocity := *parent.Velocity
+	velocity.tick = 0
+	sprite := *parent.Sprite
+	sprite.Asset = assets.Random("fry")
+	l := Layer{
+		Position:  &position,
+		Velocity:  &velocity,
+		Sprite:    &sprite,
+		Animation: &Animation{Frame: frameTurn},
+		Behavior:  &Behavior{Think: thinkSwim},
+		Lifetime: &Lifetime{
+			Exit: exitAhead,
+			life: newLife(0, parent.Asset),
+		},
+		Identity: newRandIdentity(),
+	}
+	l.turns = l.life.lifespan
+	return &l
+}
@@ -0,0 +1,123 @@
+package layer
+
+import (
+	"testing"
+)
+
+// adult creates a mortal adult fish of the species at a position.
+func adult(t *testing.T, species string, x int, y int) *Layer {
+	l := fish(t, species, x, y, 1)
+	l.MakeMortal()
+	l.life.age = growAge
+	return l
+}
+
+func TestLive(t *testing.T) {
+	const h = 20
+	tests := []struct {
+		name  string
+		layer func(t *testing.T) *Layer
+		// the draws the layer lives for
+		draws  int
+		hidden bool
+		adult  string
+	}{
+		{
+			name:  "fry grows up",
+			layer: func(t *testing.T) *Layer { return NewFry(adult(t, "tetra", 10, 5)) },
+			draws: growAge,
+			adult: "tetra",
+		},
+		{
+			name: "fry at the bottom grows up within the water",
+			layer: func(t *testing.T) *Layer {
+				fry := NewFry(adult(t, "angelfish", 10, 5))
+				fry.Place(fry.X, h-fry.Asset.Height)
+				return fry
+			},
+			draws: growAge,
+			adult: "angelfish",
+		},
+		{
+			name: "old fish dies",
+			layer: func(t *testing.T) *Layer {
+				l := adult(t, "tetra", 10, 5)
+				l.life.age = l.life.lifespan - 1
+				return l
+			},
+			draws:  1,
+			hidden: true,
+			adult:  "tetra",
+		},
+		{
+			name:  "immortal fish lives forever",
+			layer: func(t *testing.T) *Layer { return fish(t, "tetra", 10, 5, 1) },
+			draws: maxLifespan,
+			adult: "tetra",
+		},
+	}
+	for _, tt := range tests {
+		l := tt.layer(t)
+		for i := 0; i < tt.draws; i++ {
+			l.Live(h)
+		}
+		if l.Hidden() != tt.hidden {
+			t.Errorf("%s: hidden = %t, want %t", tt.name, l.Hidden(), tt.hidden)
+		}
+		if !l.Adult() || l.Asset.Species != tt.adult {
+			t.Errorf("%s: adult = %t %q, want %q", tt.name, l.Adult(), l.Asset.Species, tt.adult)
+		}
+		if l.Y < 0 || l.Y+l.Asset.Height > h {
+			t.Errorf("%s: fish is out of the water at y=%d", tt.name, l.Y)
+		}
+	}
+}
+
+func TestBreed(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b func(t *testing.T) *Layer
+		fry  bool
+	}{
+		{
+			name: "adults of a species",
+			a:    func(t *testing.T) *Layer { return adult(t, "tetra", 10, 5) },
+			b:    func(t *testing.T) *Layer { return adult(t, "tetra", 14, 6) },
+			fry:  true,
+		},
+		{
+			name: "adults of different species",
+			a:    func(t *testing.T) *Layer { return adult(t, "tetra", 10, 5) },
+			b:    func(t *testing.T) *Layer { return adult(t, "minnow", 14, 6) },
+		},
+		{
+			name: "adults far apart",
+			a:    func(t *testing.T) *Layer { return adult(t, "tetra", 10, 5) },
+			b:    func(t *testing.T) *Layer { return adult(t, "tetra", 50, 5) },
+		},
+		{
+			name: "fry",
+			a:    func(t *testing.T) *Layer { return adult(t, "tetra", 10, 5) },
+			b:    func(t *testing.T) *Layer { return NewFry(adult(t, "tetra", 14, 6)) },
+		},
+		{
+			name: "immortal fish",
+			a:    func(t *testing.T) *Layer { return adult(t, "tetra", 10, 5) },
+			b:    func(t *testing.T) *Layer { return fish(t, "tetra", 14, 6, 1) },
+		},
+	}
+	for _, tt := range tests {
+		a, b := tt.a(t), tt.b(t)
+		var fry *Layer
+		// on average they breed every few hundred draws
+		for i := 0; i < breedRate*50 && fry == nil; i++ {
+			fry = a.Breed(b)
+		}
+		if (fry != nil) != tt.fry {
+			t.Errorf("%s: fry = %v, want fry %t", tt.name, fry, tt.fry)
+		}
+		if fry != nil && (fry.Adult() || fry.Species() != a.Species()) {
+			t.Errorf("%s: fry is an adult = %t of %q", tt.name, fry.Adult(), fry.Species())
+		}
+	}
+}
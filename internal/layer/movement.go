@@ -0,0 +1,263 @@
+package layer
+
+import (
+	"math"
+
+	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/assets"
+)
+
+// A Movement computes the next position of a layer. It is called once per
+// draw with the current size of the screen.
+type Movement func(l *Layer, w int, h int)
+
+// All the movement models that can be selected by name in an asset.
+var Movements = map[string]Movement{
+	"straight": moveStraight,
+	"rise":     moveRise,
+	"sink":     moveSink,
+	"walk":     moveWalk,
+	"bob":      moveBob,
+	"wander":   moveWander,
+	"pulse":    movePulse,
+	"dart":     moveDart,
+	"turn":     moveTurn,
+}
+
+func movementFor(a assets.Asset, fallback Movement) Movement {
+	if m, ok := Movements[a.Movement]; ok {
+		return m
+	}
+	return fallback
+}
+
+func clamp(n int, lo int, hi int) int {
+	if hi < lo {
+		return lo
+	}
+	return min(max(n, lo), hi)
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
+
+func onScreen(l *Layer, w int) bool {
+	return l.X > 0 && l.X+l.Asset.Width < w
+}
+
+// flip mirrors the layer to the other source and reverses its direction.
+func (l *Layer) flip() {
+	l.Velo *= -1
+	if len(l.Asset.Sources) > 1 {
+		l.AssetIndex = 1 - l.AssetIndex
+	}
+}
+
+// The amount of draws a turning layer stops before it swims back.
+const turnDuration = 3
+
+// Turn stops the layer and lets it swim back in the opposite direction
+// after a short while. A layer only turns as often as it has turns left.
+func (l *Layer) Turn() {
+	if l.Behavior == nil || l.Frozen() || l.turning > 0 || l.turns <= 0 {
+		return
+	}
+	l.turns--
+	l.turning = turnDuration
+}
+
+// TurnAround turns the layer even if it has no turns left.
+func (l *Layer) TurnAround() {
+	if l.Behavior != nil && !l.Frozen() && l.turning == 0 {
+		l.turns++
+		l.Turn()
+	}
+}
+
+func (l *Layer) Turning() bool { return l.Behavior != nil && l.turning > 0 }
+
+// frameTurn shows the turn frame while turning if the asset provides one.
+func frameTurn(l *Layer) int {
+	if l.Turning() && l.Asset.HasTurnFrame() {
+		return assets.TurnFrame
+	}
+	return l.AssetIndex
+}
+
+func moveStraight(l *Layer, w int, h int) { l.X += l.Velo }
+
+// moveRise rises up while wobbling sideways.
+func moveRise(l *Layer, w int, h int) {
+	l.Y += l.Velo
+	l.X = l.originX + int(math.Round(math.Sin(float64(l.tick)/2)))
+}
+
+// moveSink slowly sinks down to the bottom of the water and stays there.
+func moveSink(l *Layer, w int, h int) {
+	if l.tick%2 == 0 {
+		l.Y = min(l.Y+l.Velo, h-l.Asset.Height)
+	}
+}
+
+// moveWalk moves in short bursts and rests in between.
+func moveWalk(l *Layer, w int, h int) {
+	if l.pause > 0 {
+		l.pause--
+		return
+	}
+	l.X += l.Velo
+	l.steps--
+	if l.steps <= 0 {
+		l.steps = internal.IntRand(10) + 3
+		l.pause = internal.IntRand(20) + 5
+	}
+}
+
+// moveBob swims straight while bobbing up and down on a sine wave.
+func moveBob(l *Layer, w int, h int) {
+	l.X += l.Velo
+	dy := int(math.Round(math.Sin(float64(l.tick)/3) * 2))
+	l.Y = clamp(l.originY+dy, 0, h-l.Asset.Height)
+}
+
+// moveWander swims into its direction but randomly drifts up and down.
+func moveWander(l *Layer, w int, h int) {
+	l.X += sign(l.Velo)
+	l.Y = clamp(l.Y+internal.Choose(-1, 0, 0, 0, 1), 0, h-l.Asset.Height)
+}
+
+// movePulse rises with a short pulse and slowly sinks back down, while
+// drifting sideways like a jellyfish.
+func movePulse(l *Layer, w int, h int) {
+	phase := l.tick % 12
+	switch {
+	case phase < 3:
+		l.Y--
+	case phase%2 == 0:
+		l.Y++
+	}
+	l.Y = clamp(l.Y, 0, h-l.Asset.Height)
+	if l.tick%3 == 0 {
+		l.X += sign(l.Velo)
+	}
+}
+
+// moveDart accelerates to twice its velocity and decelerates again.
+func moveDart(l *Layer, w int, h int) {
+	phase := l.tick % 16
+	speed := phase / 2
+	if phase >= 8 {
+		speed = (16 - phase) / 2
+	}
+	l.X += sign(l.Velo) * speed * internal.Abs(l.Velo) / 2
+}
+
+// moveTurn swims straight but turns around mid-screen a lot more often.
+func moveTurn(l *Layer, w int, h int) {
+	if onScreen(l, w) && internal.IntRand(40) == 0 {
+		l.TurnAround()
+	}
+	l.X += l.Velo
+}
+
+// Freeze stops the layer or lets it go on again.
+func (l *Layer) Freeze(frozen bool) {
+	if l.Velocity != nil {
+		l.frozen = frozen
+	}
+}
+
+func (l *Layer) Frozen() bool { return l.Velocity != nil && l.frozen }
+
+// Rest lets the layer skip its next movement.
+func (l *Layer) Rest() {
+	if l.Velocity != nil {
+		l.slowed = true
+	}
+}
+
+// Avoid steers the layer out of the way of another layer it is about to
+// overlap with. It shifts vertically into the nearest free lane, preferring
+// the side away from the other layer, and waits while a lane further away
+// than a row is reached. Without any free lane within the water height it
+// turns around and gives way.
+func (l *Layer) Avoid(o *Layer, h int) {
+	if l.Frozen() {
+		return
+	}
+	away := 1
+	if l.Y+l.Asset.Height/2 < o.Y+o.Asset.Height/2 {
+		away = -1
+	}
+	for d := 1; d < h; d++ {
+		for _, dy := range []int{away * d, -away * d} {
+			next := l.Bounds()
+			next.X += l.Velo
+			next.Y += dy
+			if next.Y < 0 || next.Y+next.H > h || next.Overlaps(o.Bounds()) {
+				continue
+			}
+			step := sign(dy)
+			l.Y += step
+			l.originY += step
+			if d > 1 {
+				l.slowed = true
+			}
+			return
+		}
+	}
+	l.TurnAround()
+}
+
+// Drift pushes the layer along with the water current. The current is
+// accumulated until it adds up to whole cells. It never pushes a layer out
+// of the water, but also doesnt pull it back in.
+func (l *Layer) Drift(dx float64, dy float64, w int, h int) {
+	if l.Frozen() {
+		return
+	}
+	l.driftX += dx
+	l.driftY += dy
+	x, y := int(l.driftX), int(l.driftY)
+	l.driftX -= float64(x)
+	l.driftY -= float64(y)
+	nextX := clamp(l.X+x, min(l.X, 0), max(l.X, w-l.Asset.Width))
+	l.originX += nextX - l.X
+	l.X = nextX
+	next := clamp(l.Y+y, min(l.Y, 0), max(l.Y, h-l.Asset.Height))
+	l.originY += next - l.Y
+	l.Y = next
+}
+
+// Steer moves the layer by whole cells within the water height.
+func (l *Layer) Steer(dx int, dy int, h int) {
+	if l.Frozen() {
+		return
+	}
+	l.X += dx
+	l.originX += dx
+	next := clamp(l.Y+dy, 0, h-l.Asset.Height)
+	l.originY += next - l.Y
+	l.Y = next
+}
+
+// Place puts the layer at a new position and moves its wobble along.
+func (l *Layer) Place(x int, y int) {
+	l.originX += x - l.X
+	l.originY += y - l.Y
+	l.X, l.Y = x, y
+}
+
+// Scatter lets the layer flee from a position. It darts away and turns
+// around if it was heading towards it.
+func (l *Layer) Scatter(x int, y int, h int) {
+	dx, dy := sign(l.X-x), sign(l.Y-y)
+	if sign(l.Velo) != dx {
+		l.TurnAround()
+	}
+	l.Steer(dx*2, dy, h)
+}
@@ -0,0 +1,245 @@
+package layer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/assets"
+)
+
+// screen is a screen of the size with the water above the floor.
+func screen(w int, h int) tcell.Screen {
+	sc := tcell.NewSimulationScreen("")
+	sc.Init()
+	sc.SetSize(w, h+FloorHeight)
+	return sc
+}
+
+// fish creates a fish of the species at a position, swimming with the
+// velocity, that never turns on its own.
+func fish(t *testing.T, species string, x int, y int, velo int) *Layer {
+	a, ok := assets.Find("fish", species)
+	if !ok {
+		t.Fatalf("species `%s` doesnt exist", species)
+	}
+	l := NewFish(a, 100, 100)
+	l.Place(x, y)
+	l.Z = 0
+	l.Velo = velo
+	l.AssetIndex = 0
+	if velo < 0 {
+		l.AssetIndex = 1
+	}
+	l.turns = 0
+	return l
+}
+
+// swim moves the fish like the renderer does, so they avoid each other.
+func swim(sc tcell.Screen, layers ...*Layer) {
+	_, h := sc.Size()
+	for _, l := range layers {
+		next := l.Bounds()
+		next.X += l.Velo
+		for _, o := range layers {
+			if o == l || o.Z != l.Z || l.Turning() {
+				continue
+			}
+			if next.Overlaps(o.Bounds()) && !l.Bounds().Overlaps(o.Bounds()) {
+				l.Avoid(o, h-FloorHeight)
+				break
+			}
+		}
+		Move(l, sc)
+	}
+}
+
+func TestAvoid(t *testing.T) {
+	tests := []struct {
+		name string
+		// the height of the water
+		h    int
+		a, b func(t *testing.T) *Layer
+	}{
+		{
+			name: "head on",
+			h:    30,
+			a:    func(t *testing.T) *Layer { return fish(t, "angelfish", 22, 10, 1) },
+			b:    func(t *testing.T) *Layer { return fish(t, "angelfish", 36, 10, -1) },
+		},
+		{
+			name: "head on off by a row",
+			h:    30,
+			a:    func(t *testing.T) *Layer { return fish(t, "angelfish", 22, 10, 1) },
+			b:    func(t *testing.T) *Layer { return fish(t, "angelfish", 36, 11, -1) },
+		},
+		{
+			name: "head on at the top",
+			h:    30,
+			a:    func(t *testing.T) *Layer { return fish(t, "angelfish", 22, 0, 1) },
+			b:    func(t *testing.T) *Layer { return fish(t, "angelfish", 36, 0, -1) },
+		},
+		{
+			name: "head on without room",
+			h:    8,
+			a:    func(t *testing.T) *Layer { return fish(t, "angelfish", 22, 0, 1) },
+			b:    func(t *testing.T) *Layer { return fish(t, "angelfish", 36, 0, -1) },
+		},
+		{
+			name: "overtake",
+			h:    30,
+			a:    func(t *testing.T) *Layer { return fish(t, "angelfish", 0, 10, 3) },
+			b:    func(t *testing.T) *Layer { return fish(t, "angelfish", 16, 10, 1) },
+		},
+	}
+	for _, tt := range tests {
+		sc := screen(200, tt.h)
+		a, b := tt.a(t), tt.b(t)
+		startA, startB := a.X, b.X
+		for i := 0; i < 200; i++ {
+			swim(sc, a, b)
+		}
+		// either a passed b or one of them gave way, but they never stay
+		// in front of each other
+		passed := a.X > b.X+b.Asset.Width || a.Bounds().Y >= b.Y+b.Asset.Height || b.Bounds().Y >= a.Y+a.Asset.Height
+		gaveWay := a.Velo < 0 || b.Velo > 0
+		if !passed && !gaveWay {
+			t.Errorf("%s: fish are stuck at x=%d and x=%d", tt.name, a.X, b.X)
+		}
+		if a.X == startA || b.X == startB {
+			t.Errorf("%s: fish didnt move from x=%d and x=%d", tt.name, a.X, b.X)
+		}
+	}
+}
+
+// tick runs all the systems on the layer once, like the renderer does.
+func tick(l *Layer, sc tcell.Screen) {
+	for _, system := range Systems {
+		system(l, sc)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name string
+		// the side the walker comes in from
+		left bool
+	}{
+		{"from the left", true},
+		{"from the right", false},
+	}
+	for _, tt := range tests {
+		sc := screen(80, 20)
+		w, h := sc.Size()
+		var l *Layer
+		for l == nil || (l.Velo > 0) != tt.left {
+			l = NewRandWalker(w, h)
+		}
+		floor := h - FloorHeight - l.Asset.Height
+		moved, rested := 0, 0
+		for i := 0; i < 5000 && !l.Hidden(); i++ {
+			x := l.X
+			tick(l, sc)
+			if l.Y != floor {
+				t.Fatalf("%s: walker left the floor at y=%d, want y=%d", tt.name, l.Y, floor)
+			}
+			if l.X == x {
+				rested++
+			} else {
+				moved++
+			}
+		}
+		// walkers walk in short bursts with pauses in between, until they
+		// leave the screen on the other side
+		if moved == 0 || rested == 0 {
+			t.Errorf("%s: walker moved %d and rested %d times", tt.name, moved, rested)
+		}
+		if !l.Hidden() {
+			t.Errorf("%s: walker didnt leave at x=%d", tt.name, l.X)
+		}
+	}
+}
+
+func TestMovements(t *testing.T) {
+	tests := []struct {
+		movement string
+		// the row the fish starts at, negative rows count from the bottom
+		y int
+	}{
+		{"bob", 0},
+		{"bob", -1},
+		{"wander", 0},
+		{"wander", -1},
+		{"pulse", 0},
+		{"pulse", -1},
+		{"sink", 0},
+		{"sink", -1},
+		{"straight", 0},
+		{"dart", -1},
+	}
+	for _, tt := range tests {
+		sc := screen(1000, 20)
+		_, h := sc.Size()
+		water := h - FloorHeight
+		l := fish(t, "angelfish", 100, 0, 1)
+		if tt.y < 0 {
+			l.Place(l.X, water-l.Asset.Height+tt.y+1)
+		}
+		l.Movement = Movements[tt.movement]
+		for i := 0; i < 500; i++ {
+			Move(l, sc)
+			if l.Y < 0 || l.Y > water-l.Asset.Height {
+				t.Fatalf("%s from y=%d: fish left the water at y=%d", tt.movement, tt.y, l.Y)
+			}
+		}
+	}
+}
+
+func TestTurn(t *testing.T) {
+	tests := []struct {
+		name  string
+		w     int
+		x     int
+		velo  int
+		turns int
+		// the model that moves the fish, if it isnt straight
+		movement string
+		frozen   bool
+		// if the fish swims back rather than out of the screen
+		back bool
+	}{
+		{name: "at the right edge", w: 80, x: 60, velo: 1, turns: 1, back: true},
+		{name: "at the left edge", w: 80, x: 3, velo: -1, turns: 1, back: true},
+		{name: "fast at the right edge", w: 80, x: 50, velo: 3, turns: 1, back: true},
+		{name: "without turns left", w: 80, x: 60, velo: 1},
+		{name: "frozen", w: 80, x: 60, velo: 1, turns: 1, frozen: true},
+		{name: "mid screen", w: 3000, x: 1000, velo: 1, movement: "turn", back: true},
+	}
+	for _, tt := range tests {
+		sc := screen(tt.w, 20)
+		l := fish(t, "tetra", tt.x, 5, tt.velo)
+		l.turns = tt.turns
+		if tt.movement != "" {
+			l.Movement = Movements[tt.movement]
+		}
+		l.Freeze(tt.frozen)
+		for i := 0; i < 1000 && !l.Hidden(); i++ {
+			tick(l, sc)
+			if l.turning == 0 && sign(l.Velo) != sign(tt.velo) {
+				break
+			}
+		}
+		back := sign(l.Velo) != sign(tt.velo)
+		if back != tt.back {
+			t.Errorf("%s: fish swims back = %t at x=%d, want %t", tt.name, back, l.X, tt.back)
+		}
+		if back && (l.Hidden() || !onScreen(l, tt.w)) {
+			t.Errorf("%s: fish turned off the screen at x=%d", tt.name, l.X)
+		}
+		if !back && !tt.frozen && !l.Hidden() {
+			t.Errorf("%s: fish didnt leave the screen at x=%d", tt.name, l.X)
+		}
+		if tt.frozen && l.X != tt.x {
+			t.Errorf("%s: fish moved to x=%d", tt.name, l.X)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package layer
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/gdamore/tcell"
 	"github.com/lukasjoc/nemo/internal"
 )
@@ -32,6 +35,98 @@ var Colors = append([]tcell.Style{
 	tcell.StyleDefault.Dim(true).Bold(true).Foreground(tcell.ColorLimeGreen),
 }, Blues...)
 
+var foodStyle = tcell.StyleDefault.Bold(true).Foreground(tcell.ColorSandyBrown)
+
+// NOTE: glowing styles are not dimmed, they are meant to stand out in the dark.
+var Glows = []tcell.Style{
+	tcell.StyleDefault.Bold(true).Foreground(tcell.ColorAqua),
+	tcell.StyleDefault.Bold(true).Foreground(tcell.ColorLime),
+	tcell.StyleDefault.Bold(true).Foreground(tcell.ColorFuchsia),
+	tcell.StyleDefault.Bold(true).Foreground(tcell.ColorYellow),
+}
+
+// A Theme are the palettes everything in the tank is drawn with.
+type Theme struct {
+	Colors []tcell.Style
+	Blues  []tcell.Style
+	Glows  []tcell.Style
+	// Draws the body parts in the color of the creature instead of their
+	// own colors.
+	Plain bool
+}
+
+func dimmed(colors ...tcell.Color) []tcell.Style {
+	styles := []tcell.Style{}
+	for _, c := range colors {
+		styles = append(styles, tcell.StyleDefault.Dim(true).Bold(true).Foreground(c))
+	}
+	return styles
+}
+
+func bright(colors ...tcell.Color) []tcell.Style {
+	styles := []tcell.Style{}
+	for _, c := range colors {
+		styles = append(styles, tcell.StyleDefault.Bold(true).Foreground(c))
+	}
+	return styles
+}
+
+// NewTheme creates a theme from plain colors, which are dimmed like the
+// default colors except for the glowing ones.
+func NewTheme(colors []tcell.Color, blues []tcell.Color, glows []tcell.Color, plain bool) Theme {
+	return Theme{Colors: dimmed(colors...), Blues: dimmed(blues...), Glows: bright(glows...), Plain: plain}
+}
+
+// All the themes that can be selected by name.
+var Themes = map[string]Theme{
+	"default": {Colors: Colors, Blues: Blues, Glows: Glows},
+	"ocean": {
+		Colors: dimmed(tcell.ColorAquaMarine, tcell.ColorCadetBlue, tcell.ColorMediumSeaGreen,
+			tcell.ColorTurquoise, tcell.ColorSteelBlue, tcell.ColorLightSeaGreen),
+		Blues: Blues,
+		Glows: bright(tcell.ColorAqua, tcell.ColorDeepSkyBlue),
+	},
+	"mono": {
+		Colors: dimmed(tcell.ColorWhite, tcell.ColorSilver, tcell.ColorLightGray, tcell.ColorDarkGray),
+		Blues:  dimmed(tcell.ColorSilver, tcell.ColorLightGray),
+		Glows:  bright(tcell.ColorWhite),
+		Plain:  true,
+	},
+	"neon": {
+		Colors: bright(tcell.ColorAqua, tcell.ColorLime, tcell.ColorFuchsia, tcell.ColorYellow,
+			tcell.ColorHotPink, tcell.ColorOrangeRed),
+		Blues: bright(tcell.ColorAqua, tcell.ColorDeepSkyBlue),
+		Glows: bright(tcell.ColorWhite, tcell.ColorYellow),
+		Plain: true,
+	},
+}
+
+// ThemeNames lists the names of all the themes.
+func ThemeNames() []string {
+	names := []string{}
+	for name := range Themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// plain is set by the theme, see `Theme.Plain`.
+var plain bool
+
+// UseTheme draws everything created from now on with the theme of the name.
+func UseTheme(name string) error {
+	t, ok := Themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme `%s`", name)
+	}
+	t.Use()
+	return nil
+}
+
+// Use draws everything created from now on with the theme.
+func (t Theme) Use() { Colors, Blues, Glows, plain = t.Colors, t.Blues, t.Glows, t.Plain }
+
 func bodypartColorMask(ch rune) tcell.Style {
 	style := tcell.StyleDefault.Dim(true).Bold(true)
 	switch ch {
@@ -46,3 +141,31 @@ func bodypartColorMask(ch rune) tcell.Style {
 	}
 	return style
 }
+
+func sandColorMask(ch rune) tcell.Style {
+	style := tcell.StyleDefault.Dim(true)
+	switch ch {
+	case 'o', 'O', '0':
+		return style.Foreground(tcell.ColorDarkGray)
+	case '~', '-':
+		return style.Foreground(tcell.ColorDarkKhaki)
+	}
+	return style.Foreground(tcell.ColorBurlyWood)
+}
+
+// shadeDepth darkens the style the further away its depth plane is.
+func shadeDepth(style tcell.Style, z int) tcell.Style {
+	if z <= 0 {
+		return style
+	}
+	fg, _, _ := style.Decompose()
+	r, g, b := fg.RGB()
+	// NOTE: the default color claims to be white, but it is up to the
+	// terminal
+	if r < 0 || fg == tcell.ColorDefault {
+		return style
+	}
+	f := 1 - float64(z)/float64(DepthPlanes+1)
+	return style.Foreground(tcell.NewRGBColor(
+		int32(float64(r)*f), int32(float64(g)*f), int32(float64(b)*f)))
+}
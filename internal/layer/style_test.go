@@ -0,0 +1,46 @@
+package layer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+// brightness is the sum of the color channels of the foreground.
+func brightness(style tcell.Style) int32 {
+	fg, _, _ := style.Decompose()
+	r, g, b := fg.RGB()
+	return r + g + b
+}
+
+func TestShadeDepth(t *testing.T) {
+	lit := tcell.StyleDefault.Bold(true).Foreground(tcell.NewRGBColor(200, 160, 120))
+	tests := []struct {
+		name  string
+		style tcell.Style
+		z     int
+		// the brightness of the shaded style, or -1 if it stays the same
+		want int32
+	}{
+		{"nearest plane", lit, 0, -1},
+		{"middle plane", lit, 1, (200 + 160 + 120) * 3 / 4},
+		{"furthest plane", lit, DepthPlanes - 1, (200 + 160 + 120) * 2 / 4},
+		{"default color", tcell.StyleDefault, DepthPlanes - 1, -1},
+	}
+	for _, tt := range tests {
+		got := shadeDepth(tt.style, tt.z)
+		if tt.want < 0 {
+			if got != tt.style {
+				t.Errorf("%s: style = %v, want it unchanged", tt.name, got)
+			}
+			continue
+		}
+		// NOTE: every channel is rounded down on its own
+		if b := brightness(got); b < tt.want-3 || b > tt.want {
+			t.Errorf("%s: brightness = %d, want %d", tt.name, b, tt.want)
+		}
+		if _, _, attrs := got.Decompose(); attrs&tcell.AttrBold == 0 {
+			t.Errorf("%s: lost the attributes of the style", tt.name)
+		}
+	}
+}
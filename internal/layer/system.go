@@ -0,0 +1,105 @@
+package layer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell"
+)
+
+// A System acts on the components of a layer once per tick. Systems skip
+// the layers that dont have the components they need.
+type System func(l *Layer, sc tcell.Screen)
+
+// All the systems in the order the renderer runs them.
+var Systems = []System{Render, Expire, Behave, Move}
+
+// Render draws the sprite of the layer at its position.
+// NOTE: that the systems dont actually update the screen. Its up to the
+// renderer to sync the changes to the screen. This effectively allows for
+// double buffering.
+func Render(l *Layer, sc tcell.Screen) {
+	if l.Position == nil || l.Sprite == nil {
+		return
+	}
+	drawTiles(l, sc)
+}
+
+// Expire hides the layers that left the screen and ages the mortal ones.
+func Expire(l *Layer, sc tcell.Screen) {
+	if l.Lifetime == nil || l.Frozen() {
+		return
+	}
+	w, h := sc.Size()
+	if l.Exit != nil && l.Exit(l, w, h) {
+		l.hidden = true
+	}
+	l.Live(h - FloorHeight)
+}
+
+// Behave lets the layer decide what to do next.
+func Behave(l *Layer, sc tcell.Screen) {
+	if l.Behavior == nil || l.Think == nil || l.Frozen() {
+		return
+	}
+	w, h := sc.Size()
+	l.Think(l, w, h-FloorHeight)
+}
+
+// Move computes the next position of the layer.
+func Move(l *Layer, sc tcell.Screen) {
+	if l.Position == nil || l.Velocity == nil || l.frozen {
+		return
+	}
+	w, h := sc.Size()
+	l.tick++
+	if l.Behavior != nil && l.turning > 0 {
+		l.turning--
+		if l.turning == 0 {
+			l.flip()
+		}
+		return
+	}
+	if l.slowed {
+		l.slowed = false
+		return
+	}
+	// layers further away only move every few draws
+	if l.tick%(l.Z+1) != 0 {
+		return
+	}
+	// the floor is not part of the water
+	l.Movement(l, w, h-FloorHeight)
+}
+
+// NOTE: the renderer clears the screen before every frame, so there is no
+// need to clean up any trails from the previous position here.
+// NOTE: spaces within the outline of a tile are drawn so that the layer
+// occludes the layers behind it, all the other spaces are transparent.
+func drawTiles(l *Layer, sc tcell.Screen) {
+	ty := l.Y
+	for _, tile := range l.Asset.Sources[l.frame()] {
+		start := strings.IndexFunc(tile, isVisible)
+		end := strings.LastIndexFunc(tile, isVisible)
+		tx := l.X
+		for i, r := range tile {
+			if isVisible(r) {
+				sc.SetContent(tx, ty, r, nil, shadeDepth(l.mask(l, r), l.Z))
+			} else if i > start && i < end {
+				sc.SetContent(tx, ty, ' ', nil, tcell.StyleDefault)
+			}
+			tx++
+		}
+		ty++
+	}
+}
+
+func isVisible(r rune) bool { return !unicode.IsSpace(r) }
+
+// frame is the index of the source to draw.
+func (l *Layer) frame() int {
+	if l.Animation == nil || l.Frame == nil {
+		return l.AssetIndex
+	}
+	return l.Frame(l)
+}
@@ -0,0 +1,136 @@
+package lighting
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+type Mode int
+
+const (
+	// No lighting at all, everything is drawn in its original colors.
+	Off Mode = iota
+	// The light follows the wall clock, it is darkest at midnight.
+	Clock
+	// A whole day passes within the cycle duration.
+	Accelerated
+)
+
+// The duration of a whole day in the accelerated mode by default.
+const DefaultCycle = time.Minute * 5
+
+// Below this light level it is considered night.
+const darkLevel = 0.3
+
+var (
+	dayWater   = [3]float64{0, 35, 70}
+	nightWater = [3]float64{0, 0, 12}
+)
+
+type Light struct {
+	Mode Mode
+	// The duration of a whole day in the accelerated mode.
+	Cycle time.Duration
+	start time.Time
+	level float64
+}
+
+func New(mode Mode, cycle time.Duration) *Light {
+	return &Light{Mode: mode, Cycle: cycle, start: time.Now(), level: 1}
+}
+
+// Parse creates the lighting of a name, which is `clock` to follow the wall
+// clock, `fast` for a whole day every `DefaultCycle` or empty for no
+// lighting at all.
+func Parse(name string) (*Light, error) {
+	switch name {
+	case "":
+		return New(Off, DefaultCycle), nil
+	case "clock":
+		return New(Clock, 0), nil
+	case "fast":
+		return New(Accelerated, DefaultCycle), nil
+	}
+	return nil, fmt.Errorf("unknown light `%s`", name)
+}
+
+// Update computes the light level at the given time. The level follows a
+// cosine that peaks at noon.
+func (l *Light) Update(ts time.Time) {
+	var day float64
+	switch l.Mode {
+	case Clock:
+		midnight := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, ts.Location())
+		day = ts.Sub(midnight).Hours() / 24
+	case Accelerated:
+		if l.Cycle <= 0 {
+			l.Cycle = DefaultCycle
+		}
+		// start the accelerated cycle in the morning
+		day = float64(ts.Sub(l.start)%l.Cycle)/float64(l.Cycle) + 0.25
+	default:
+		l.level = 1
+		return
+	}
+	l.level = (1 - math.Cos(2*math.Pi*day)) / 2
+}
+
+// Level is the current light level from 0 (midnight) to 1 (noon).
+func (l *Light) Level() float64 { return l.level }
+
+func (l *Light) Dark() bool { return l.level < darkLevel }
+
+// Activity is the fraction of creatures that are active at the current
+// light level.
+func (l *Light) Activity() float64 { return 0.35 + 0.65*l.level }
+
+func (l *Light) water() tcell.Color {
+	var c [3]int32
+	for i := range c {
+		c[i] = int32(nightWater[i] + (dayWater[i]-nightWater[i])*l.level)
+	}
+	return tcell.NewRGBColor(c[0], c[1], c[2])
+}
+
+// Shade dims the foreground of the style according to the light level and
+// puts it in front of the water.
+func (l *Light) Shade(style tcell.Style) tcell.Style {
+	if l.Mode == Off {
+		return style
+	}
+	fg, _, _ := style.Decompose()
+	if r, g, b := fg.RGB(); r >= 0 {
+		f := 0.35 + 0.65*l.level
+		style = style.Foreground(tcell.NewRGBColor(
+			int32(float64(r)*f), int32(float64(g)*f), int32(float64(b)*f)))
+	}
+	return style.Background(l.water())
+}
+
+// Glow puts the style in front of the water but keeps its foreground, as
+// bioluminescent creatures shine in their own light.
+func (l *Light) Glow(style tcell.Style) tcell.Style {
+	if l.Mode == Off {
+		return style
+	}
+	return style.Background(l.water())
+}
+
+// Screen passes every style drawn through a shading function.
+type Screen struct {
+	tcell.Screen
+	shade func(tcell.Style) tcell.Style
+}
+
+func (sc Screen) SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style) {
+	sc.Screen.SetContent(x, y, mainc, combc, sc.shade(style))
+}
+
+// Lit wraps the screen to draw everything shaded by the light.
+func (l *Light) Lit(sc tcell.Screen) tcell.Screen { return Screen{sc, l.Shade} }
+
+// Glowing wraps the screen to draw everything glowing.
+func (l *Light) Glowing(sc tcell.Screen) tcell.Screen { return Screen{sc, l.Glow} }
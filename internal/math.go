@@ -6,3 +6,10 @@ func intMax(n int, n2 int) int {
 	}
 	return n
 }
+
+func Abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -1,9 +1,31 @@
 package internal
 
-import "math/rand"
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	rngMu sync.Mutex
+	rng   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Seed makes all the random choices repeatable.
+func Seed(seed int64) {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	rng = rand.New(rand.NewSource(seed))
+}
+
+func intn(n int) int {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	return rng.Intn(n)
+}
 
 func Choose[T any](selection ...T) T {
-	return selection[rand.Intn(len(selection))]
+	return selection[intn(len(selection))]
 }
 
-func IntRand(n int) int { return rand.Intn(intMax(n, 1)) }
+func IntRand(n int) int { return intn(intMax(n, 1)) }
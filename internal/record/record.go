@@ -0,0 +1,157 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/event"
+)
+
+// NOTE: recordings are asciicasts (version 2), so they can be replayed with
+// asciinema as well.
+
+type header struct {
+	Version   int   `json:"version"`
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+// A Recorder writes the frames of a screen to a recording. Every frame only
+// contains the cells that changed since the frame before.
+type Recorder struct {
+	out   io.Writer
+	start time.Time
+	w     int
+	h     int
+	last  []event.Cell
+}
+
+// New starts a recording of a screen of the given size.
+func New(out io.Writer, w int, h int, start time.Time) (*Recorder, error) {
+	data, err := json.Marshal(header{Version: 2, Width: w, Height: h, Timestamp: start.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(out, "%s\n", data); err != nil {
+		return nil, err
+	}
+	return &Recorder{out: out, start: start, w: w, h: h}, nil
+}
+
+// Frame records the cells of a screen, row by row.
+func (r *Recorder) Frame(cells [][]event.Cell, ts time.Time) error {
+	w, h := 0, len(cells)
+	if h > 0 {
+		w = len(cells[0])
+	}
+	var sb strings.Builder
+	if w != r.w || h != r.h || r.last == nil {
+		// start over with a clear screen if its size changed
+		r.w, r.h = w, h
+		r.last = make([]event.Cell, w*h)
+		sb.WriteString("\x1b[0m\x1b[2J")
+	}
+	style := tcell.Style(-1)
+	x0, y0 := -1, -1
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			mainc, s := cells[y][x].Rune, cells[y][x].Style
+			if mainc == 0 {
+				mainc = ' '
+			}
+			c := event.Cell{Rune: mainc, Style: s}
+			if r.last[y*w+x] == c {
+				continue
+			}
+			r.last[y*w+x] = c
+			if x != x0 || y != y0 {
+				fmt.Fprintf(&sb, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if s != style {
+				sb.WriteString(sgr(s))
+				style = s
+			}
+			sb.WriteRune(mainc)
+			x0, y0 = x+1, y
+		}
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	data, err := json.Marshal([]any{ts.Sub(r.start).Seconds(), "o", sb.String()})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(r.out, "%s\n", data)
+	return err
+}
+
+// sgr is the escape sequence that switches the terminal to the style.
+func sgr(s tcell.Style) string {
+	fg, bg, attr := s.Decompose()
+	codes := []string{"0"}
+	for _, a := range []struct {
+		mask tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, "1"},
+		{tcell.AttrDim, "2"},
+		{tcell.AttrUnderline, "4"},
+		{tcell.AttrBlink, "5"},
+		{tcell.AttrReverse, "7"},
+	} {
+		if attr&a.mask != 0 {
+			codes = append(codes, a.code)
+		}
+	}
+	// NOTE: the default color would be white, as it isnt told apart from
+	// an RGB color
+	if r, g, b := fg.RGB(); fg != tcell.ColorDefault && r >= 0 {
+		codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+	}
+	if r, g, b := bg.RGB(); bg != tcell.ColorDefault && r >= 0 {
+		codes = append(codes, fmt.Sprintf("48;2;%d;%d;%d", r, g, b))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// Replay plays a recording back to the terminal in real time, until it
+// ends or the context is done.
+func Replay(ctx context.Context, in io.Reader, out io.Writer) error {
+	s := bufio.NewScanner(in)
+	s.Buffer(nil, 16*1024*1024)
+	if !s.Scan() {
+		return fmt.Errorf("recording is empty")
+	}
+	var h header
+	if err := json.Unmarshal(s.Bytes(), &h); err != nil || h.Version != 2 {
+		return fmt.Errorf("not a recording")
+	}
+	io.WriteString(out, "\x1b[?25l\x1b[2J")
+	defer io.WriteString(out, "\x1b[0m\x1b[?25h\r\n")
+	start := time.Now()
+	for s.Scan() {
+		var frame []any
+		if err := json.Unmarshal(s.Bytes(), &frame); err != nil || len(frame) != 3 {
+			return fmt.Errorf("invalid frame `%s`", s.Text())
+		}
+		t, _ := frame[0].(float64)
+		data, _ := frame[2].(string)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(start.Add(time.Duration(t * float64(time.Second))))):
+		}
+		if _, err := io.WriteString(out, data); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
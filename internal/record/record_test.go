@@ -0,0 +1,144 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/event"
+)
+
+// screen makes the cells of a screen from its rows.
+func screen(style tcell.Style, rows ...string) [][]event.Cell {
+	cells := [][]event.Cell{}
+	for _, row := range rows {
+		line := []event.Cell{}
+		for _, r := range row {
+			line = append(line, event.Cell{Rune: r, Style: style})
+		}
+		cells = append(cells, line)
+	}
+	return cells
+}
+
+func TestFrame(t *testing.T) {
+	plain := tcell.StyleDefault
+	red := plain.Foreground(tcell.NewRGBColor(255, 0, 0))
+	tests := []struct {
+		name   string
+		before [][]event.Cell
+		cells  [][]event.Cell
+		// the output of the frame, empty if nothing is written
+		want string
+	}{
+		{
+			name:  "first frame",
+			cells: screen(plain, "ab", "cd"),
+			want:  "\x1b[0m\x1b[2J\x1b[1;1H\x1b[0mab\x1b[2;1Hcd",
+		},
+		{
+			name:   "unchanged",
+			before: screen(plain, "ab", "cd"),
+			cells:  screen(plain, "ab", "cd"),
+			want:   "",
+		},
+		{
+			name:   "changed cell",
+			before: screen(plain, "ab", "cd"),
+			cells:  screen(plain, "ab", "cx"),
+			want:   "\x1b[2;2H\x1b[0mx",
+		},
+		{
+			name:   "changed style",
+			before: screen(plain, "ab"),
+			cells:  screen(red, "ab"),
+			want:   "\x1b[1;1H\x1b[0;38;2;255;0;0mab",
+		},
+		{
+			name:   "resized",
+			before: screen(plain, "ab"),
+			cells:  screen(plain, "a"),
+			want:   "\x1b[0m\x1b[2J\x1b[1;1H\x1b[0ma",
+		},
+		{
+			name:  "empty cells are spaces",
+			cells: [][]event.Cell{{{Rune: 0, Style: plain}}},
+			want:  "\x1b[0m\x1b[2J\x1b[1;1H\x1b[0m ",
+		},
+	}
+	start := time.Unix(1700000000, 0)
+	for _, tt := range tests {
+		var out bytes.Buffer
+		r, err := New(&out, 2, 2, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.before != nil {
+			if err := r.Frame(tt.before, start); err != nil {
+				t.Fatal(err)
+			}
+		}
+		out.Reset()
+		if err := r.Frame(tt.cells, start.Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if out.Len() > 0 {
+			var frame []any
+			if err := json.Unmarshal(out.Bytes(), &frame); err != nil || len(frame) != 3 {
+				t.Errorf("%s: invalid frame %q", tt.name, out.String())
+				continue
+			}
+			if frame[0] != 1.0 || frame[1] != "o" {
+				t.Errorf("%s: frame = %v, want it after 1s", tt.name, frame[:2])
+			}
+			got, _ = frame[2].(string)
+		}
+		if got != tt.want {
+			t.Errorf("%s: frame = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSgr(t *testing.T) {
+	plain := tcell.StyleDefault
+	tests := []struct {
+		style tcell.Style
+		want  string
+	}{
+		{plain, "\x1b[0m"},
+		{plain.Bold(true).Reverse(true), "\x1b[0;1;7m"},
+		{plain.Dim(true).Underline(true).Blink(true), "\x1b[0;2;4;5m"},
+		{plain.Foreground(tcell.NewRGBColor(1, 2, 3)), "\x1b[0;38;2;1;2;3m"},
+		{plain.Background(tcell.NewRGBColor(4, 5, 6)), "\x1b[0;48;2;4;5;6m"},
+		{plain.Foreground(tcell.ColorRed), "\x1b[0;38;2;255;0;0m"},
+	}
+	for _, tt := range tests {
+		if got := sgr(tt.style); got != tt.want {
+			t.Errorf("sgr(%v) = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var rec bytes.Buffer
+	r, err := New(&rec, 1, 1, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Frame(screen(tcell.StyleDefault, "x"), r.start)
+	var out bytes.Buffer
+	if err := Replay(context.Background(), &rec, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "x") {
+		t.Errorf("replay = %q, doesnt show the frame", out.String())
+	}
+	if err := Replay(context.Background(), strings.NewReader("nope\n"), &out); err == nil {
+		t.Errorf("replay of something else doesnt fail")
+	}
+}
@@ -0,0 +1,159 @@
+package renderer
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/layer"
+)
+
+// view is the part of the tank that is shown on the screen. Everything in
+// the tank is drawn in tank coordinates and the view moves it onto the
+// screen.
+type view struct {
+	tcell.Screen
+	x int
+	w int
+}
+
+func (v view) SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style) {
+	v.Screen.SetContent(x-v.x, y, mainc, combc, style)
+}
+
+func (v view) GetContent(x int, y int) (rune, []rune, tcell.Style, int) {
+	return v.Screen.GetContent(x-v.x, y)
+}
+
+// Size is the size of the whole tank.
+func (v view) Size() (int, int) {
+	_, h := v.Screen.Size()
+	return v.w, h
+}
+
+func (r *Renderer) view() tcell.Screen { return view{r.Screen, r.viewX, r.w} }
+
+// offscreen is the tank without the part shown on the screen. Layers that
+// are simulated there are not drawn at all.
+type offscreen struct{ view }
+
+func (offscreen) SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style) {}
+
+// viewBounds is the part of the tank that is shown on the screen.
+func (r *Renderer) viewBounds() layer.Rect {
+	return layer.Rect{X: r.viewX, W: r.screenW, H: r.h}
+}
+
+// The amount of cells the view moves when scrolling.
+const scrollStep = 8
+
+// Scroll moves the view through the tank and stops following a creature.
+func (r *Renderer) Scroll(dx int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.following = nil
+	r.viewX = min(max(r.viewX+dx*scrollStep, 0), r.w-r.screenW)
+}
+
+// ToggleMinimap shows or hides the map of the whole tank, which is only
+// shown if the tank is wider than the screen.
+func (r *Renderer) ToggleMinimap() {
+	r.mu.Lock()
+	r.hideMinimap = !r.hideMinimap
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// SetMinimap shows or hides the map of the whole tank.
+func (r *Renderer) SetMinimap(show bool) {
+	r.mu.Lock()
+	r.hideMinimap = !show
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// The height of the minimap, the width depends on the width of the screen.
+const minimapHeight = 6
+
+// renderMinimap draws a map of the whole tank into the lower left corner
+// with a mark for every creature and the part shown on the screen
+// highlighted.
+func (r *Renderer) renderMinimap() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hideMinimap || r.w <= r.screenW || r.h <= minimapHeight {
+		return
+	}
+	w, h := max(r.screenW/4, 10), minimapHeight
+	y0 := r.h - h
+	style := tcell.StyleDefault.Dim(true).Reverse(true)
+	seen := style.Dim(false)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s := style
+			if tx := x * r.w / w; tx >= r.viewX && tx < r.viewX+r.screenW {
+				s = seen
+			}
+			ch := ' '
+			if y == h-1 {
+				ch = '_'
+			}
+			r.Screen.SetContent(x, y0+y, ch, nil, s)
+		}
+	}
+	for _, l := range r.creatures() {
+		x := min(max((l.X+l.Asset.Width/2)*w/r.w, 0), w-1)
+		y := min(max((l.Y+l.Asset.Height/2)*h/r.h, 0), h-1)
+		ch := '>'
+		if l.Velocity == nil || l.Velo == 0 {
+			ch = '.'
+		} else if l.Velo < 0 {
+			ch = '<'
+		}
+		_, _, s, _ := r.Screen.GetContent(x, y0+y)
+		r.Screen.SetContent(x, y0+y, ch, nil, s)
+	}
+}
+
+// Follow lets the view follow the creature selected in the inspector, or
+// a random fish on the screen if there is none. Following again stops it.
+func (r *Renderer) Follow() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.following != nil {
+		r.following = nil
+		return
+	}
+	if r.selected != nil {
+		r.following = r.selected
+		return
+	}
+	screen := r.viewBounds()
+	fish := []*layer.Layer{}
+	for _, l := range layer.Visible(r.swarm) {
+		if l.Bounds().Overlaps(screen) {
+			fish = append(fish, l)
+		}
+	}
+	if len(fish) > 0 {
+		r.following = internal.Choose(fish...)
+	}
+}
+
+func (r *Renderer) Following() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.following != nil
+}
+
+// moveCamera centers the view on the creature it follows and stops
+// following it once it left the tank.
+func (r *Renderer) moveCamera() {
+	if r.following == nil {
+		return
+	}
+	if r.following.Hidden() {
+		r.following = nil
+		return
+	}
+	x := r.following.X + r.following.Asset.Width/2 - r.screenW/2
+	r.viewX = min(max(x, 0), r.w-r.screenW)
+}
@@ -0,0 +1,368 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/assets"
+	"github.com/lukasjoc/nemo/internal/current"
+	"github.com/lukasjoc/nemo/internal/layer"
+	"github.com/lukasjoc/nemo/internal/text"
+)
+
+// The bounds of the tick delay when speeding up or slowing down.
+const (
+	MinTickDelay = time.Millisecond * 15
+	MaxTickDelay = time.Second
+)
+
+// SetTickDelay changes the render speed while running.
+func (r *Renderer) SetTickDelay(d time.Duration) {
+	r.mu.Lock()
+	r.TickDelay = min(max(d, MinTickDelay), MaxTickDelay)
+	if !r.paused {
+		r.t.Reset(r.TickDelay)
+	}
+	r.mu.Unlock()
+	// show the new speed even while stopped
+	r.redraw()
+}
+
+// SpeedUp makes the simulation run one step faster.
+func (r *Renderer) SpeedUp() { r.SetTickDelay(r.TickDelay * 2 / 3) }
+
+// SlowDown makes the simulation run one step slower.
+func (r *Renderer) SlowDown() { r.SetTickDelay(r.TickDelay * 3 / 2) }
+
+// Speed is how fast the simulation runs compared to the tick delay the
+// renderer was created with.
+func (r *Renderer) Speed() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.speed()
+}
+
+func (r *Renderer) speed() float64 {
+	return float64(r.baseDelay) / float64(r.TickDelay)
+}
+
+// Paused reports if the render loop is stopped.
+func (r *Renderer) Paused() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.paused
+}
+
+// Step simulates and draws exactly one tick while the renderer is stopped.
+// It does nothing while running.
+func (r *Renderer) Step() {
+	if !r.Paused() {
+		return
+	}
+	r.draw(time.Now())
+}
+
+// AddFish lets another random fish swim in.
+func (r *Renderer) AddFish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addFish(r.newRandFish(r.w, r.h-layer.FloorHeight))
+}
+
+// newRandFish creates a random fish of a species that wasnt taken out.
+func (r *Renderer) newRandFish(w int, h int) *layer.Layer {
+	asset := assets.Random("fish")
+	// NOTE: give up at some point, in case every species was taken out
+	for i := 0; r.removed[asset.Species] && i < 16; i++ {
+		asset = assets.Random("fish")
+	}
+	return layer.NewFish(asset, w, h)
+}
+
+// AddSpecies lets a school of n fish of the given species swim in.
+func (r *Renderer) AddSpecies(species string, n int) error {
+	asset, ok := assets.Find("fish", species)
+	if !ok {
+		return fmt.Errorf("species `%s` doesnt exist", species)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.added = append(slices.DeleteFunc(r.added, func(s string) bool { return s == species }), species)
+	delete(r.removed, species)
+	for i := 0; i < n; i++ {
+		r.addFish(layer.NewFish(asset, r.w, r.h-layer.FloorHeight))
+	}
+	return nil
+}
+
+func (r *Renderer) addFish(l *layer.Layer) {
+	if r.swarm == nil {
+		return
+	}
+	if r.Lifecycle {
+		l.MakeMortal()
+	}
+	r.SwarmSize++
+	r.keepDensity()
+	r.swarm = append(r.swarm, r.spawn(l))
+}
+
+// SetSwarmSize adds or removes random fish until there are n of them.
+func (r *Renderer) SetSwarmSize(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.swarm != nil && len(r.swarm) < n {
+		r.addFish(r.newRandFish(r.w, r.h-layer.FloorHeight))
+	}
+	for len(r.swarm) > n {
+		r.despawn(r.swarm[len(r.swarm)-1])
+		r.swarm = r.swarm[:len(r.swarm)-1]
+	}
+	r.SwarmSize = n
+	r.keepDensity()
+}
+
+// Configure changes the settings while running. The function is called
+// while holding the lock, so it can safely change the exported fields and
+// anything else the render loop uses, like the theme. A tick delay it
+// changes is the new normal speed.
+func (r *Renderer) Configure(fn func()) {
+	r.mu.Lock()
+	delay := r.TickDelay
+	fn()
+	if r.TickDelay != delay {
+		r.TickDelay = min(max(r.TickDelay, MinTickDelay), MaxTickDelay)
+		r.baseDelay = r.TickDelay
+		if !r.paused {
+			r.t.Reset(r.TickDelay)
+		}
+	}
+	switch {
+	case !r.Current.Streaks:
+		r.particles = nil
+	case r.particles == nil && r.swarm != nil:
+		r.particles = make([]current.Particle, r.w*r.h/120)
+	}
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// RemoveFish takes the last fish out of the tank.
+func (r *Renderer) RemoveFish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.swarm) == 0 {
+		return
+	}
+	r.SwarmSize = max(r.SwarmSize-1, 0)
+	r.keepDensity()
+	r.despawn(r.swarm[len(r.swarm)-1])
+	r.swarm = r.swarm[:len(r.swarm)-1]
+}
+
+// RemoveSpecies takes all the fish of a species out of the tank and returns
+// how many there were. No more fish of it swim in, until it is added again.
+func (r *Renderer) RemoveSpecies(species string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.added = slices.DeleteFunc(r.added, func(s string) bool { return s == species })
+	if r.removed == nil {
+		r.removed = map[string]bool{}
+	}
+	r.removed[species] = true
+	kept := []*layer.Layer{}
+	for _, l := range r.swarm {
+		if l != nil && l.Species() == species {
+			r.despawn(l)
+			continue
+		}
+		kept = append(kept, l)
+	}
+	removed := len(r.swarm) - len(kept)
+	if r.swarm != nil {
+		r.swarm = kept
+	}
+	r.SwarmSize = max(r.SwarmSize-removed, 0)
+	r.keepDensity()
+	return removed
+}
+
+// LastSpecies is the species that was added last and wasnt taken out since,
+// or an empty string if there is none.
+func (r *Renderer) LastSpecies() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.added) == 0 {
+		return ""
+	}
+	return r.added[len(r.added)-1]
+}
+
+// ToggleStats shows or hides the stats.
+func (r *Renderer) ToggleStats() {
+	r.mu.Lock()
+	r.showStats = !r.showStats
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// SetStats shows or hides the stats.
+func (r *Renderer) SetStats(show bool) {
+	r.mu.Lock()
+	r.showStats = show
+	r.mu.Unlock()
+	r.redraw()
+}
+
+func (r *Renderer) statsShown() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return internal.DebugEnabled || r.showStats
+}
+
+// ToggleHelp shows or hides an overlay with the given lines.
+func (r *Renderer) ToggleHelp(lines []string) {
+	r.mu.Lock()
+	if r.help == nil {
+		r.help = lines
+	} else {
+		r.help = nil
+	}
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// SetNotice shows a box with the given lines above the floor, apart from
+// the help. No lines hide it.
+func (r *Renderer) SetNotice(lines []string) {
+	r.mu.Lock()
+	r.notice = lines
+	r.mu.Unlock()
+	r.redraw()
+}
+
+func (r *Renderer) renderHelp() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.help == nil {
+		return
+	}
+	lines := text.Wrap(r.help, r.screenW-8)
+	r.box(lines, (r.h-len(lines)-2)/2)
+}
+
+func (r *Renderer) renderNotice() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.notice) == 0 {
+		return
+	}
+	lines := text.Wrap(r.notice, r.screenW-8)
+	r.box(lines, r.h-len(lines)-2-layer.FloorHeight)
+}
+
+// box draws the lines in a box at row y, in the middle of the screen.
+func (r *Renderer) box(lines []string, y int) {
+	w, h := text.Width(lines), len(lines)
+	x := (r.screenW - w - 4) / 2
+	style := tcell.StyleDefault.Reverse(true)
+	text.Fill(r.Screen, x, y, w+4, h+2, style)
+	text.Draw(r.Screen, x+2, y+1, w, h, lines, text.Left, style)
+}
+
+// Restyle picks new colors for everything in the tank from the current
+// theme, see `layer.Theme.Use`.
+func (r *Renderer) Restyle() {
+	r.mu.Lock()
+	r.nameStyle = internal.Choose(layer.Colors...)
+	for _, layers := range [][]*layer.Layer{r.swarm, r.walkers, r.glowers, r.bubbles, r.food} {
+		for _, l := range layers {
+			if l != nil {
+				l.Restyle()
+			}
+		}
+	}
+	r.mu.Unlock()
+	if r.Paused() {
+		r.repaint()
+	}
+	r.redraw()
+}
+
+type cell struct {
+	mainc rune
+	combc []rune
+	style tcell.Style
+}
+
+// capture remembers the screen before the overlays are drawn.
+func (r *Renderer) capture() {
+	w, h := r.Screen.Size()
+	r.scene = resized(r.scene, w*h, cell{})
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := &r.scene[y*w+x]
+			c.mainc, c.combc, c.style, _ = r.Screen.GetContent(x, y)
+		}
+	}
+}
+
+// repaint draws the tank as it is without simulating a tick and captures
+// it, so the overlays are drawn on top of it while stopped.
+func (r *Renderer) repaint() {
+	r.mu.Lock()
+	r.Screen.Clear()
+	r.renderParticles()
+	r.mu.Unlock()
+	r.renderName()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paint(r.floor)
+	for _, layers := range [][]*layer.Layer{r.walkers, r.glowers, r.food, r.byDepth()} {
+		for _, l := range layers {
+			r.paint(l)
+		}
+	}
+	r.capture()
+}
+
+// redraw draws the overlays again on top of the last frame while the
+// renderer is stopped, as it wont draw another one by itself.
+func (r *Renderer) redraw() {
+	if !r.Paused() {
+		return
+	}
+	r.mu.Lock()
+	w, h := r.Screen.Size()
+	if len(r.scene) == w*h {
+		for i, c := range r.scene {
+			r.Screen.SetContent(i%w, i/w, c.mainc, c.combc, c.style)
+		}
+	}
+	r.mu.Unlock()
+	r.renderOverlays(time.Now())
+	r.Screen.Show()
+}
+
+// Screenshot writes the current screen as plain text.
+func (r *Renderer) Screenshot(out io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, h := r.Screen.Size()
+	var sb strings.Builder
+	for y := 0; y < h; y++ {
+		line := make([]rune, w)
+		for x := 0; x < w; x++ {
+			line[x], _, _, _ = r.Screen.GetContent(x, y)
+		}
+		sb.WriteString(strings.TrimRight(string(line), " \x00"))
+		sb.WriteByte('\n')
+	}
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
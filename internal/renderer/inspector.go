@@ -0,0 +1,132 @@
+package renderer
+
+import (
+	"slices"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/layer"
+	"github.com/lukasjoc/nemo/internal/text"
+)
+
+// ToggleInspector shows or hides the cursor and the panel with the live
+// state of the selected creature.
+func (r *Renderer) ToggleInspector() {
+	r.mu.Lock()
+	r.inspecting = !r.inspecting
+	if r.inspecting {
+		r.cursorX, r.cursorY = r.screenW/2, r.h/2
+	}
+	r.selected = nil
+	r.mu.Unlock()
+	r.redraw()
+}
+
+func (r *Renderer) Inspecting() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.inspecting
+}
+
+// MoveCursor moves the cursor of the inspector by a few cells and selects
+// the creature under it.
+func (r *Renderer) MoveCursor(dx int, dy int) {
+	r.mu.RLock()
+	x, y := r.cursorX+dx, r.cursorY+dy
+	r.mu.RUnlock()
+	r.Select(x, y)
+}
+
+// Select moves the cursor of the inspector to a position and selects the
+// creature there. It keeps the last selection if there is none.
+func (r *Renderer) Select(x int, y int) {
+	r.mu.Lock()
+	if !r.inspecting {
+		r.mu.Unlock()
+		return
+	}
+	r.cursorX = min(max(x, 0), r.screenW-1)
+	r.cursorY = min(max(y, 0), r.h-1)
+	if l := r.at(r.cursorX+r.viewX, r.cursorY); l != nil {
+		r.selected = l
+	}
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// SelectNext selects the next creature on the screen from left to right and moves the
+// cursor onto it.
+func (r *Renderer) SelectNext() {
+	r.mu.Lock()
+	screen := r.viewBounds()
+	creatures := slices.DeleteFunc(r.creatures(), func(l *layer.Layer) bool {
+		return !l.Bounds().Overlaps(screen)
+	})
+	if !r.inspecting || len(creatures) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	slices.SortStableFunc(creatures, func(a *layer.Layer, b *layer.Layer) int { return a.X - b.X })
+	next := creatures[0]
+	if i := slices.Index(creatures, r.selected); i >= 0 {
+		next = creatures[(i+1)%len(creatures)]
+	}
+	r.selected = next
+	r.cursorX = min(max(next.X-r.viewX, 0), r.screenW-1)
+	r.cursorY = min(max(next.Y, 0), r.h-1)
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// FreezeSelected stops the selected creature or lets it go on again.
+func (r *Renderer) FreezeSelected() {
+	r.mu.Lock()
+	if r.selected != nil {
+		r.selected.Freeze(!r.selected.Frozen())
+	}
+	r.mu.Unlock()
+	r.redraw()
+}
+
+// RemoveSelected takes the selected creature out of the tank for good, a
+// fish doesnt get replaced by another one.
+func (r *Renderer) RemoveSelected() {
+	r.mu.Lock()
+	if l := r.selected; l != nil {
+		l.Remove()
+		r.despawn(l)
+		if slices.Contains(r.swarm, l) {
+			r.SwarmSize = max(r.SwarmSize-1, 0)
+			r.keepDensity()
+		}
+		drop := func(layers []*layer.Layer) []*layer.Layer {
+			return slices.DeleteFunc(layers, func(o *layer.Layer) bool { return o == l })
+		}
+		r.swarm, r.walkers, r.glowers = drop(r.swarm), drop(r.walkers), drop(r.glowers)
+		r.selected = nil
+	}
+	r.mu.Unlock()
+	r.flush()
+	r.redraw()
+}
+
+func (r *Renderer) renderInspector() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.inspecting {
+		return
+	}
+	if r.selected != nil && r.selected.Hidden() {
+		r.selected = nil
+	}
+	lines := []string{"Nothing selected"}
+	if r.selected != nil {
+		lines = r.selected.Inspect()
+	}
+	lines = append([]string{"Inspector", ""}, lines...)
+	w, h := text.Width(lines), len(lines)
+	style := tcell.StyleDefault.Reverse(true)
+	text.Fill(r.Screen, 0, 0, w+2, h+2, style)
+	text.Draw(r.Screen, 1, 1, w, h, lines, text.Left, style)
+	ch, _, cs, _ := r.Screen.GetContent(r.cursorX, r.cursorY)
+	r.Screen.SetContent(r.cursorX, r.cursorY, ch, nil, cs.Reverse(true))
+}
@@ -0,0 +1,170 @@
+package renderer
+
+import (
+	"math"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/current"
+	"github.com/lukasjoc/nemo/internal/event"
+	"github.com/lukasjoc/nemo/internal/layer"
+)
+
+const (
+	// The distance within which fish scatter from a clicked fish.
+	scatterDistance = 15
+	// The distance within which fish notice food.
+	smellDistance = 25
+	// The amount of ticks the current of a drag lasts.
+	dragTicks = 50
+)
+
+// at finds the nearest creature at a position.
+func (r *Renderer) at(x int, y int) *layer.Layer {
+	var found *layer.Layer
+	for _, l := range r.creatures() {
+		if l.Bounds().Contains(x, y) && (found == nil || l.Z < found.Z) {
+			found = l
+		}
+	}
+	return found
+}
+
+// creatures are all the layers that have an identity.
+func (r *Renderer) creatures() []*layer.Layer {
+	all := []*layer.Layer{}
+	for _, group := range [][]*layer.Layer{r.swarm, r.glowers, r.walkers} {
+		for _, l := range group {
+			if l != nil && !l.Hidden() && l.Identity != nil {
+				all = append(all, l)
+			}
+		}
+	}
+	return all
+}
+
+// Click scatters the fish around a clicked creature or drops food into
+// the water.
+func (r *Renderer) Click(x int, y int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.swarm == nil {
+		return
+	}
+	x += r.viewX
+	h := r.h - layer.FloorHeight
+	if r.at(x, y) == nil {
+		if y < h {
+			r.food = append(r.food, r.spawn(layer.NewFood(x, y)))
+		}
+		return
+	}
+	for _, l := range r.swarm {
+		if l == nil || internal.Abs(l.X-x) > scatterDistance || internal.Abs(l.Y-y) > scatterDistance/2 {
+			continue
+		}
+		l.Scatter(x, y, h)
+	}
+}
+
+// Blow lets a bubble rise from a position.
+func (r *Renderer) Blow(x int, y int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.swarm == nil || y >= r.h-layer.FloorHeight {
+		return
+	}
+	r.bubbles = append(r.bubbles, r.spawn(layer.NewBubble(x+r.viewX, y)))
+}
+
+// Drag creates a gust of current along the dragged line.
+func (r *Renderer) Drag(x0 int, y0 int, x1 int, y1 int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, h := float64(r.w), float64(r.h-layer.FloorHeight)
+	if w <= 0 || h <= 0 {
+		return
+	}
+	dx, dy := float64(x1-x0)/8, float64(y1-y0)/8
+	if d := math.Hypot(dx, dy); d > 2 {
+		dx, dy = dx/d*2, dy/d*2
+	}
+	cx, cy := float64(x0+x1+2*r.viewX)/2/w, float64(y0+y1)/2/h
+	r.Current.Gust(current.Push(cx, cy, 0.25, dx, dy), dragTicks)
+}
+
+// Hover shows a tooltip for the creature at the position.
+func (r *Renderer) Hover(x int, y int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hoverX, r.hoverY = x, y
+}
+
+func (r *Renderer) renderTooltip() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hoverX < 0 {
+		return
+	}
+	l := r.at(r.hoverX+r.viewX, r.hoverY)
+	if l == nil {
+		return
+	}
+	tip := " " + l.Name + " the " + l.Species() + " "
+	x := min(r.hoverX+1, r.screenW-len(tip))
+	y := max(r.hoverY-1, 0)
+	style := tcell.StyleDefault.Reverse(true)
+	for i, ch := range tip {
+		r.Screen.SetContent(x+i, y, ch, nil, style)
+	}
+}
+
+func (r *Renderer) renderFood() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.food = r.prune(r.food)
+	for _, l := range r.food {
+		r.drift(l)
+		internal.Logln("LAYER DRAW %v", l)
+		r.step(l)
+	}
+}
+
+// feed lets the fish swim towards the closest food in front of them and
+// eat it once they reach it.
+func (r *Renderer) feed(l *layer.Layer) {
+	h := r.h - layer.FloorHeight
+	var closest *layer.Layer
+	for _, f := range r.food {
+		if f.Hidden() {
+			continue
+		}
+		mx, my := l.Mouth()
+		if f.Bounds().Contains(mx, my) || f.Bounds().Overlaps(l.Bounds()) {
+			f.Eat()
+			r.emit(event.Eat{Eater: l, Food: f})
+			return
+		}
+		dx := f.X - mx
+		if internal.Abs(dx) > smellDistance || internal.Abs(f.Y-my) > smellDistance/2 {
+			continue
+		}
+		if closest == nil || internal.Abs(dx) < internal.Abs(closest.X-mx) {
+			closest = f
+		}
+	}
+	if closest == nil {
+		return
+	}
+	mx, my := l.Mouth()
+	if (closest.X-mx)*l.Velo < 0 {
+		l.TurnAround()
+	}
+	if closest.Y != my {
+		dy := 1
+		if closest.Y < my {
+			dy = -1
+		}
+		l.Steer(0, dy, h)
+	}
+}
@@ -1,19 +1,31 @@
 package renderer
 
 import (
+	"cmp"
 	"fmt"
-	"strings"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/gdamore/tcell"
 	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/clock"
+	"github.com/lukasjoc/nemo/internal/current"
+	"github.com/lukasjoc/nemo/internal/event"
 	"github.com/lukasjoc/nemo/internal/layer"
+	"github.com/lukasjoc/nemo/internal/lighting"
+	"github.com/lukasjoc/nemo/internal/script"
+	"github.com/lukasjoc/nemo/internal/text"
 )
 
 const DefaultTickDelay = time.Millisecond * 120
 
+// On average every fish blows a bubble every `DefaultBubbleRate` ticks.
+const DefaultBubbleRate = 16
+
 type Renderer struct {
+	// guards everything below. Helpers that dont lock it themselves expect
+	// to be called while it is held.
 	mu        sync.RWMutex
 	done      chan bool
 	t         *time.Ticker
@@ -22,56 +34,158 @@ type Renderer struct {
 	nameStyle tcell.Style
 	swarm     []*layer.Layer
 	bubbles   []*layer.Layer
+	walkers   []*layer.Layer
+	glowers   []*layer.Layer
+	food      []*layer.Layer
+	floor     *layer.Layer
+	particles []current.Particle
+	systems   []layer.System
+	showStats bool
+	// if the render loop is stopped.
+	paused bool
+	// the species that were added by hand, the last one last, and the ones
+	// that were taken out, which dont swim in anymore.
+	added   []string
+	removed map[string]bool
+	// the fish per cell of water, see keepDensity.
+	density float64
+	// the tick delay the renderer was created with, which is normal speed.
+	baseDelay time.Duration
+	// the lines of the help overlay, nil if it isnt shown.
+	help []string
+	// the lines of the notice, see SetNotice.
+	notice []string
+	// the last known position of the mouse, or -1 before it moved.
+	hoverX int
+	hoverY int
+	// the tank is w by h cells, which might be wider than the screen. The
+	// screen shows the part of the tank that starts at viewX.
+	screenW int
+	viewX   int
+	// the creature the view follows, if any.
+	following   *layer.Layer
+	hideMinimap bool
+	// the events of the current frame, which are published after it.
+	pending []event.Event
+	// the screen as it was drawn before the overlays, so they can be drawn
+	// again while stopped.
+	scene []cell
+	// the cursor and the creature it selected in the inspector.
+	inspecting bool
+	cursorX    int
+	cursorY    int
+	selected   *layer.Layer
+	// the lines of the banner or the timer that is shown instead.
+	banner []string
+	// the remaining ticks of the alarm after a countdown ran out.
+	alarm int
 	// A initialized tcell screen instance.
 	Screen tcell.Screen
-	// The amount of random fish to generate. This is static for now, but
-	// planned to be dynamic in the future.
+	// The lines of the banner in the lower right corner of the screen. As
+	// defined in `render.NameTiles` it is the nemo logo by default.
+	Banner []string
+	// The font the timer is drawn with. Without a font it is plain text.
+	Font *text.Font
+	// The clock, stopwatch or countdown shown instead of the banner. By
+	// default the timer is off.
+	Timer *clock.Timer
+	// The width of the tank as a multiple of the width of the screen. By
+	// default the tank is as wide as the screen.
+	WorldScale int
+	// The amount of random fish to generate. It changes as fish are added
+	// or removed while running.
 	SwarmSize int
 	// A delay to reduce the render speed with.
 	// As defined in `render.DefaultTickDelay` the default delay is 120ms.
 	TickDelay time.Duration
+	// The average amount of ticks between two bubbles of the same fish.
+	// As defined in `render.DefaultBubbleRate` the default rate is 16.
+	BubbleRate int
+	// The lighting model that shades everything drawn. By default lighting
+	// is turned off.
+	Light *lighting.Light
+	// Simulates the lifecycle of the fish. Instead of being replaced when
+	// they leave, fish grow up, breed and eventually die.
+	Lifecycle bool
+	// The water current that pushes all the fish and bubbles around. By
+	// default there is no current.
+	Current *current.Field
+	// The behavior scripts by the species they drive.
+	Scripts map[string]*script.Script
+	// Publishes everything that happens in the tank.
+	Events *event.Bus
 	// Signals if the renderer has been stopped recently. This can be used
 	// as a hook to stop and start the renderer.
 	Stopped chan bool
 }
 
+// Stop stops the render loop and waits until it is done with the tick it
+// might be drawing. As event handlers of the frames run on the render loop,
+// they cant stop the renderer themselves.
 func (r *Renderer) Stop() {
+	if r.stop() {
+		r.Events.Publish(event.Pause{Paused: true})
+	}
+}
+
+// stop stops the renderer and reports if it was running.
+func (r *Renderer) stop() bool {
 	select {
 	case <-r.Stopped:
 		go func() { r.Stopped <- true }()
+		return false
 	default:
 		r.mu.Lock()
 		r.t.Stop()
+		running := !r.paused
+		r.paused = true
 		r.mu.Unlock()
-		go func() { r.done <- true }()
+		// NOTE: the loop only takes this while it isnt drawing, so nothing
+		// is drawn behind the back of Step once it is taken
+		if running {
+			r.done <- true
+		}
 		go func() { r.Stopped <- true }()
-		if internal.DebugEnabled {
+		if r.statsShown() {
 			r.renderStats(time.Now())
 			r.mu.Lock()
 			r.Screen.Show()
 			r.mu.Unlock()
 		}
+		return true
 	}
-
 }
 
 func (r *Renderer) Start() {
+	r.start()
+	r.Events.Publish(event.Pause{Paused: false})
+}
+
+func (r *Renderer) start() {
 	r.mu.Lock()
 	r.t.Reset(r.TickDelay)
+	r.paused = false
 	r.mu.Unlock()
 	go r.render()
 }
 
+// Restart starts the tank over. It only publishes a pause event if the
+// renderer was paused before, as it runs again afterwards.
 func (r *Renderer) Restart() {
+	paused := false
 	select {
 	case <-r.Stopped:
+		paused = true
 	default:
-		r.Stop()
+		r.stop()
 		<-r.Stopped
 	}
 	r.refresh()
 	r.Reset()
-	r.Start()
+	r.start()
+	if paused {
+		r.Events.Publish(event.Pause{Paused: false})
+	}
 }
 
 func (r *Renderer) Destroy() {
@@ -79,14 +193,22 @@ func (r *Renderer) Destroy() {
 	defer r.mu.Unlock()
 	r.swarm = nil
 	r.bubbles = nil
+	r.walkers = nil
+	r.glowers = nil
+	r.food = nil
+	r.floor = nil
+	r.particles = nil
 }
 
 func (r *Renderer) refresh() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	w, h := r.Screen.Size()
-	r.w = w
+	r.screenW = w
+	r.w = w * max(r.WorldScale, 1)
 	r.h = h
+	r.viewX = min(r.viewX, r.w-r.screenW)
+	r.following = nil
 	r.Screen.Clear()
 }
 
@@ -96,39 +218,57 @@ func (r *Renderer) Reset() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.nameStyle = internal.Choose(layer.Colors...)
+	r.added, r.removed = nil, nil
+	r.keepDensity()
 	r.swarm = make([]*layer.Layer, r.SwarmSize)
 	for i := 0; i < r.SwarmSize; i++ {
-		r.swarm[i] = layer.NewRandFish(r.w, r.h)
+		r.swarm[i] = r.spawn(r.newRandFish(r.w, r.h-layer.FloorHeight))
+		if r.Lifecycle {
+			r.swarm[i].MakeMortal()
+		}
+	}
+	r.floor = layer.NewFloor(r.w, r.h)
+	r.walkers = make([]*layer.Layer, r.walkerCount())
+	for i := range r.walkers {
+		r.walkers[i] = r.spawn(layer.NewRandWalker(r.w, r.h))
 	}
-	r.bubbles = make([]*layer.Layer, r.SwarmSize)
+	r.glowers = make([]*layer.Layer, r.walkerCount())
+	r.food = []*layer.Layer{}
+	if r.Current.Streaks {
+		r.particles = make([]current.Particle, r.w*r.h/120)
+	}
+	r.bubbles = []*layer.Layer{}
 	// NOTE: the bubbles will be created and rendered as the fish moves
 	// and the x,y of the fish is known..
 }
 
-var nameRaw = `	
+var nameRaw = `
   ___  ___ __ _  ___
  / _ \/ -_)  ' \/ _ \
 /_//_/\__/_/_/_/\___/ 1.1`
 
-var nameTiles = strings.Split(nameRaw, "\n")
+// The nemo logo, which is the banner by default.
+var NameTiles = text.Lines(nameRaw)
 
-// TODO: i should have a more generic function that can render a bunch of
-// bytes to a x,y,w,h
-// func (r *Renderer) renderText(x int, y int, w int, h int, text string) { }
+func (r *Renderer) nameBounds() layer.Rect {
+	w := text.Width(r.banner)
+	return layer.Rect{
+		X: r.screenW - w - 4,
+		Y: r.h - len(r.banner) - 1 - layer.FloorHeight,
+		W: w,
+		H: len(r.banner),
+	}
+}
 
 func (r *Renderer) renderName() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	nameX := r.w - len(nameTiles[len(nameTiles)-1]) - 4
-	nameY := r.h - len(nameTiles) - 1
-	for _, tile := range nameTiles {
-		rx := nameX
-		for _, ch := range tile {
-			r.Screen.SetContent(rx, nameY, ch, nil, r.nameStyle)
-			rx++
-		}
-		nameY++
+	b := r.nameBounds()
+	style := r.nameStyle
+	if r.alarm > 0 {
+		style = style.Blink(true)
 	}
+	text.Draw(r.Light.Lit(r.Screen), b.X, b.Y, b.W, b.H, r.banner, text.Left, style)
 }
 
 func (r *Renderer) renderStats(ts time.Time) {
@@ -136,6 +276,7 @@ func (r *Renderer) renderStats(ts time.Time) {
 	defer r.mu.Unlock()
 	fishCount := 0
 	bubbleCount := 0
+	walkerCount := 0
 	for _, l := range r.swarm {
 		if l == nil {
 			continue
@@ -148,70 +289,305 @@ func (r *Renderer) renderStats(ts time.Time) {
 		}
 		bubbleCount++
 	}
-	stats := fmt.Sprintf("TS: %5d\nFish: %5d\nBubbles: %5d", ts.Unix(), fishCount, bubbleCount)
-	statsTiles := strings.Split(stats, "\n")
-	nameX := r.w - len(statsTiles[len(statsTiles)-1]) - (1)
-	nameY := 0 + len(statsTiles) - 1
-	for _, tile := range statsTiles {
-		rx := nameX
-		for _, ch := range tile {
-			r.Screen.SetContent(rx, nameY, ch, nil, tcell.StyleDefault)
-			rx++
+	for _, l := range r.walkers {
+		if l == nil {
+			continue
 		}
-		nameY++
+		walkerCount++
 	}
+	stats := fmt.Sprintf("TS: %5d\nFish: %5d\nBubbles: %5d\nWalkers: %5d\nFood: %5d\nSpeed: %4.2fx\nLight: %5.2f",
+		ts.Unix(), fishCount, bubbleCount, walkerCount, len(r.food), r.speed(), r.Light.Level())
+	statsTiles := text.Lines(stats)
+	w := text.Width(statsTiles)
+	text.Draw(r.Light.Lit(r.Screen), r.screenW-w-1, len(statsTiles)-1, w, len(statsTiles),
+		statsTiles, text.Left, tcell.StyleDefault)
 }
 
-func (r *Renderer) renderBubbles() {
+// blow lets the fish blow new bubbles where they are.
+func (r *Renderer) blow() {
+	r.bubbles = r.prune(r.bubbles)
+	for _, l := range r.swarm {
+		if l == nil || l.Turning() {
+			continue
+		}
+		bx, by := l.Mouth()
+		rate := int(float64(r.BubbleRate) / r.Light.Activity())
+		if bx < 0 || bx >= r.w || internal.IntRand(rate) != 0 {
+			continue
+		}
+		b := layer.NewBubble(bx, by)
+		b.Z = l.Z
+		r.bubbles = append(r.bubbles, r.spawn(b))
+	}
+}
+
+// byDepth are the fish and the bubbles, the ones furthest away first, so
+// the nearer ones occlude them. Bubbles are in front of the fish at the
+// same depth and the empty slots go to the end.
+func (r *Renderer) byDepth() []*layer.Layer {
+	layers := slices.Concat(r.swarm, r.bubbles)
+	slices.SortStableFunc(layers, func(a *layer.Layer, b *layer.Layer) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return 1
+		case b == nil:
+			return -1
+		}
+		return cmp.Compare(b.Z, a.Z)
+	})
+	return layers
+}
+
+func (r *Renderer) renderSwarm() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.bubbles == nil {
+	if r.swarm == nil {
 		return
 	}
-	for _, layerIndex := range layer.FindHidden(r.bubbles) {
-		r.bubbles[layerIndex] = nil
+	if r.Lifecycle {
+		r.live()
+	} else {
+		// replace every fish that left with a random one, but keep the
+		// schools of the species that were added
+		h := r.h - layer.FloorHeight
+		for _, layerIndex := range layer.FindHidden(r.swarm) {
+			l := r.swarm[layerIndex]
+			r.despawn(l)
+			next := layer.NewFish(l.Asset, r.w, h)
+			if !slices.Contains(r.added, l.Species()) {
+				next = r.newRandFish(r.w, h)
+			}
+			r.swarm[layerIndex] = r.spawn(next)
+		}
 	}
-	for i, l := range r.swarm {
+	r.frenzy()
+	r.blow()
+	// the name is an obstacle that fish would rather turn around at
+	name := r.nameBounds()
+	name.X += r.viewX
+	for _, l := range r.byDepth() {
 		if l == nil {
 			continue
 		}
-		bx := 0
-		if l.Velo < 0 {
-			bx = l.X + 1
-		} else {
-			bx = l.X + l.Asset.Width
+		if l.Asset.Group == "bubble" {
+			r.drift(l)
+			internal.Logln("LAYER DRAW %v", l)
+			r.step(l)
+			continue
+		}
+		next := l.Bounds()
+		next.X += l.Velo
+		if next.Overlaps(name) && !l.Bounds().Overlaps(name) {
+			l.Turn()
+		}
+		for _, o := range r.swarm {
+			if o == nil || o == l || o.Z != l.Z || l.Turning() {
+				continue
+			}
+			if next.Overlaps(o.Bounds()) && !l.Bounds().Overlaps(o.Bounds()) {
+				r.emit(event.Collide{Layer: l, Other: o})
+				l.Avoid(o, r.h-layer.FloorHeight)
+				break
+			}
 		}
-		if bx > 0 && (bx%(r.w/4) == 0) {
-			b := layer.NewRandBubble(r.w, r.h)
-			b.X = bx
-			b.Y = l.Y - 1
-			r.bubbles[i] = b
+		r.drift(l)
+		r.feed(l)
+		// at night not all the fish are active
+		if float64(internal.IntRand(100)) >= r.Light.Activity()*100 {
+			l.Rest()
 		}
+		internal.Logln("LAYER DRAW %v", l)
+		r.step(l)
 	}
-	for _, l := range r.bubbles {
+}
+
+// live lets all the fish breed and removes the dead ones.
+func (r *Renderer) live() {
+	h := r.h - layer.FloorHeight
+	// NOTE: the dead are taken out first, so they dont breed anymore
+	r.swarm = r.prune(r.swarm)
+	offspring := []*layer.Layer{}
+	for i, l := range r.swarm {
+		for _, o := range r.swarm[i+1:] {
+			if len(r.swarm)+len(offspring) >= r.SwarmSize*2 {
+				continue
+			}
+			if fry := l.Breed(o); fry != nil {
+				offspring = append(offspring, r.spawn(fry))
+			}
+		}
+	}
+	r.swarm = append(r.swarm, offspring...)
+	// keep the tank from dying out by letting some new fish swim in
+	for len(r.swarm) < r.SwarmSize/3+1 {
+		l := r.newRandFish(r.w, h)
+		l.MakeMortal()
+		r.swarm = append(r.swarm, r.spawn(l))
+	}
+}
+
+// The amount of bottom walkers is derived from the swarm size, there are
+// simply not as many of them.
+func (r *Renderer) walkerCount() int { return r.SwarmSize/6 + 1 }
+
+func (r *Renderer) renderFloor() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.floor == nil {
+		return
+	}
+	r.step(r.floor)
+}
+
+func (r *Renderer) renderWalkers() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.walkers == nil {
+		return
+	}
+	for _, layerIndex := range layer.FindHidden(r.walkers) {
+		r.despawn(r.walkers[layerIndex])
+		r.walkers[layerIndex] = r.spawn(layer.NewRandWalker(r.w, r.h))
+	}
+	for _, l := range r.walkers {
 		if l == nil {
 			continue
 		}
 		internal.Logln("LAYER DRAW %v", l)
-		l.Draw(l, r.Screen)
+		r.step(l)
 	}
 }
 
-func (r *Renderer) renderSwarm() {
+// drift pushes the layer along with the water current.
+func (r *Renderer) drift(l *layer.Layer) {
+	h := r.h - layer.FloorHeight
+	dx, dy := r.Current.At(l.X, l.Y, r.w, h)
+	l.Drift(dx, dy, r.w, h)
+}
+
+var streakStyle = tcell.StyleDefault.Dim(true).Foreground(tcell.ColorDarkSlateGray)
+
+func (r *Renderer) renderCurrent() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.swarm == nil {
+	r.Current.Update()
+	if r.particles == nil {
 		return
 	}
-	for _, layerIndex := range layer.FindHidden(r.swarm) {
-		r.swarm[layerIndex] = layer.NewRandFish(r.w, r.h)
+	r.Current.StepParticles(r.particles, r.w, r.h-layer.FloorHeight)
+	r.renderParticles()
+}
+
+func (r *Renderer) renderParticles() {
+	for _, p := range r.particles {
+		r.lit().SetContent(int(p.X), int(p.Y), p.Rune(), nil, streakStyle)
 	}
-	for _, l := range r.swarm {
+}
+
+// emit queues an event to be published after the current frame.
+func (r *Renderer) emit(e event.Event) { r.pending = append(r.pending, e) }
+
+func (r *Renderer) spawn(l *layer.Layer) *layer.Layer {
+	if s, ok := r.Scripts[l.Species()]; ok && l.Behavior != nil {
+		l.Think = s.Think(l, r.neighbors)
+	}
+	r.emit(event.Spawn{Layer: l})
+	return l
+}
+
+// The distance within which scripted creatures see their neighbors.
+const neighborDistance = 20
+
+// neighbors finds all the swimming creatures close to the layer.
+func (r *Renderer) neighbors(l *layer.Layer) []*layer.Layer {
+	near := []*layer.Layer{}
+	for _, o := range slices.Concat(r.swarm, r.glowers) {
+		if o == nil || o == l || o.Hidden() {
+			continue
+		}
+		if internal.Abs(o.X-l.X) <= neighborDistance && internal.Abs(o.Y-l.Y) <= neighborDistance/2 {
+			near = append(near, o)
+		}
+	}
+	return near
+}
+
+func (r *Renderer) despawn(l *layer.Layer) { r.emit(event.Despawn{Layer: l}) }
+
+// prune removes all the hidden layers and lets everyone know they left.
+func (r *Renderer) prune(layers []*layer.Layer) []*layer.Layer {
+	for _, layerIndex := range layer.FindHidden(layers) {
+		r.despawn(layers[layerIndex])
+	}
+	return layer.Visible(layers)
+}
+
+// flush publishes all the events of the last frame.
+func (r *Renderer) flush() {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	for _, e := range pending {
+		r.Events.Publish(e)
+	}
+}
+
+// step runs all the systems on the layer. Glowing layers are drawn in
+// their own light.
+func (r *Renderer) step(l *layer.Layer) {
+	sc := r.screenOf(l)
+	for _, system := range r.systems {
+		system(l, sc)
+	}
+}
+
+// paint only draws the layer where it is, see step.
+func (r *Renderer) paint(l *layer.Layer) {
+	if l != nil {
+		layer.Render(l, r.screenOf(l))
+	}
+}
+
+// screenOf is the screen the layer is drawn to.
+func (r *Renderer) screenOf(l *layer.Layer) tcell.Screen {
+	if l.Sprite != nil && l.Glow {
+		return r.Light.Glowing(r.view())
+	}
+	if l.Sprite != nil && l.Position != nil && !l.Bounds().Overlaps(r.viewBounds()) {
+		return offscreen{view{r.Screen, r.viewX, r.w}}
+	}
+	return r.lit()
+}
+
+// lit is the tank everything is drawn to, shaded by the current light.
+func (r *Renderer) lit() tcell.Screen { return r.Light.Lit(r.view()) }
+
+func (r *Renderer) renderGlowers() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.glowers == nil {
+		return
+	}
+	// glowers only come out in the dark and leave when it gets light
+	for _, layerIndex := range layer.FindHidden(r.glowers) {
+		r.despawn(r.glowers[layerIndex])
+		r.glowers[layerIndex] = nil
+	}
+	for i, l := range r.glowers {
+		if l == nil && r.Light.Dark() {
+			r.glowers[i] = r.spawn(layer.NewRandGlower(r.w, r.h-layer.FloorHeight))
+		}
+	}
+	for _, l := range r.glowers {
 		if l == nil {
 			continue
 		}
+		r.drift(l)
 		internal.Logln("LAYER DRAW %v", l)
-		l.Draw(l, r.Screen)
+		r.step(l)
 	}
 }
 
@@ -221,28 +597,87 @@ func (r *Renderer) render() {
 		case <-r.done:
 			return
 		case ts := <-r.t.C:
-			r.renderName()
-			r.renderSwarm()
-			r.renderBubbles()
-			if internal.DebugEnabled {
-				r.renderStats(ts)
-			}
-			r.Screen.Show()
+			r.draw(ts)
+		}
+	}
+}
+
+// draw simulates and draws a single tick.
+func (r *Renderer) draw(ts time.Time) {
+	r.mu.Lock()
+	r.moveCamera()
+	r.updateTimer(ts)
+	r.Light.Update(ts)
+	r.Screen.SetStyle(r.Light.Shade(tcell.StyleDefault))
+	r.Screen.Clear()
+	r.mu.Unlock()
+	r.renderCurrent()
+	r.renderName()
+	r.renderFloor()
+	r.renderWalkers()
+	r.renderGlowers()
+	r.renderFood()
+	r.renderSwarm()
+	r.mu.Lock()
+	r.capture()
+	r.mu.Unlock()
+	r.renderOverlays(ts)
+	r.Screen.Show()
+	r.mu.Lock()
+	r.emit(event.Frame{Time: ts, Cells: r.cells()})
+	r.mu.Unlock()
+	r.flush()
+}
+
+// cells copies what is on the screen.
+func (r *Renderer) cells() [][]event.Cell {
+	w, h := r.Screen.Size()
+	rows := make([][]event.Cell, h)
+	for y := range rows {
+		rows[y] = make([]event.Cell, w)
+		for x := range rows[y] {
+			mainc, _, style, _ := r.Screen.GetContent(x, y)
+			rows[y][x] = event.Cell{Rune: mainc, Style: style}
 		}
 	}
+	return rows
+}
+
+// renderOverlays draws everything that is on top of the tank.
+func (r *Renderer) renderOverlays(ts time.Time) {
+	r.renderTooltip()
+	r.renderMinimap()
+	r.renderInspector()
+	if r.statsShown() {
+		r.renderStats(ts)
+	}
+	r.renderHelp()
+	r.renderNotice()
 }
 
 func New(sc tcell.Screen, swarmSize int, tickDelay time.Duration) *Renderer {
 	r := Renderer{
-		Screen:    sc,
-		SwarmSize: swarmSize,
-		TickDelay: tickDelay,
-		mu:        sync.RWMutex{},
-		t:         time.NewTicker(tickDelay),
-		done:      make(chan bool),
-		Stopped:   make(chan bool),
-		swarm:     nil,
-		bubbles:   nil,
+		Screen:     sc,
+		SwarmSize:  swarmSize,
+		TickDelay:  tickDelay,
+		baseDelay:  tickDelay,
+		paused:     true,
+		hoverX:     -1,
+		hoverY:     -1,
+		BubbleRate: DefaultBubbleRate,
+		WorldScale: 1,
+		Banner:     NameTiles,
+		Timer:      clock.New(clock.Off),
+		Light:      lighting.New(lighting.Off, lighting.DefaultCycle),
+		Current:    current.New(),
+		systems:    layer.Systems,
+		Events:     event.NewBus(),
+		mu:         sync.RWMutex{},
+		t:          time.NewTicker(tickDelay),
+		done:       make(chan bool),
+		Stopped:    make(chan bool),
+		swarm:      nil,
+		bubbles:    nil,
 	}
 	return &r
 }
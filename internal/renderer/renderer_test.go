@@ -0,0 +1,185 @@
+package renderer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/layer"
+)
+
+// tank creates a renderer on a simulated screen of the size, with a fresh
+// tank that isnt running.
+func tank(t *testing.T, w int, h int, swarm int, lifecycle bool) *Renderer {
+	sc := tcell.NewSimulationScreen("")
+	if err := sc.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sc.Fini)
+	sc.SetSize(w, h)
+	r := New(sc, swarm, DefaultTickDelay)
+	r.Lifecycle = lifecycle
+	r.Reset()
+	return r
+}
+
+func TestRefill(t *testing.T) {
+	tests := []struct {
+		name      string
+		lifecycle bool
+		// the amount of fish that die or leave
+		removed int
+		want    int
+	}{
+		{"the lifecycle keeps a few fish", true, 12, 12/3 + 1},
+		{"the lifecycle doesnt refill", true, 4, 8},
+		{"without the lifecycle every fish is replaced", false, 12, 12},
+	}
+	for _, tt := range tests {
+		r := tank(t, 120, 40, 12, tt.lifecycle)
+		for _, l := range r.swarm[:tt.removed] {
+			l.Remove()
+		}
+		r.renderSwarm()
+		// NOTE: fry that were just born dont count, but fish that died of
+		// old age while moving do
+		fish := slices.DeleteFunc(slices.Clone(r.swarm), func(l *layer.Layer) bool { return l == nil || !l.Adult() })
+		if len(fish) != tt.want {
+			t.Errorf("%s: %d fish, want %d", tt.name, len(fish), tt.want)
+		}
+		for _, l := range fish {
+			if l.Mortal() != tt.lifecycle {
+				t.Errorf("%s: fish is mortal = %t", tt.name, l.Mortal())
+				break
+			}
+		}
+	}
+}
+
+func TestByDepth(t *testing.T) {
+	r := tank(t, 120, 40, 0, false)
+	fish := func(z int) *layer.Layer {
+		l := layer.NewRandFish(r.w, r.h)
+		l.Z = z
+		return l
+	}
+	bubble := func(z int) *layer.Layer {
+		l := layer.NewBubble(10, 10)
+		l.Z = z
+		return l
+	}
+	tests := []struct {
+		name    string
+		swarm   []*layer.Layer
+		bubbles []*layer.Layer
+	}{
+		{"fish", []*layer.Layer{fish(0), fish(2), fish(1), fish(2)}, nil},
+		{"empty slots", []*layer.Layer{nil, fish(0), nil, fish(2)}, nil},
+		{"bubbles", []*layer.Layer{fish(0), fish(2)}, []*layer.Layer{bubble(0), bubble(2), bubble(1)}},
+	}
+	for _, tt := range tests {
+		r.swarm, r.bubbles = tt.swarm, tt.bubbles
+		layers := r.byDepth()
+		if len(layers) != len(tt.swarm)+len(tt.bubbles) {
+			t.Errorf("%s: %d layers, want %d", tt.name, len(layers), len(tt.swarm)+len(tt.bubbles))
+		}
+		for i := 1; i < len(layers); i++ {
+			a, b := layers[i-1], layers[i]
+			switch {
+			case a == nil && b != nil:
+				t.Errorf("%s: empty slot before %v", tt.name, b)
+			case a == nil || b == nil:
+			case a.Z < b.Z:
+				t.Errorf("%s: depth %d before %d", tt.name, a.Z, b.Z)
+			case a.Z == b.Z && a.Asset.Group == "bubble" && b.Asset.Group != "bubble":
+				t.Errorf("%s: bubble behind a fish at depth %d", tt.name, a.Z)
+			}
+		}
+	}
+}
+
+// cells are the visible runes of the layer by their position.
+func cells(l *layer.Layer) map[[2]int]rune {
+	c := map[[2]int]rune{}
+	for dy, tile := range l.Asset.Sources[l.AssetIndex] {
+		for dx, r := range tile {
+			if r != ' ' {
+				c[[2]int{l.X + dx, l.Y + dy}] = r
+			}
+		}
+	}
+	return c
+}
+
+func TestOcclusion(t *testing.T) {
+	fish := func(species string, z int) *layer.Layer {
+		l := layer.NewRandFish(120, 40)
+		for l.Velo < 0 || l.Species() != species {
+			l = layer.NewRandFish(120, 40)
+		}
+		l.Place(20, 10)
+		l.Z = z
+		return l
+	}
+	// bubble puts a bubble where the fish shows something else
+	bubble := func(f *layer.Layer, z int) *layer.Layer {
+		b := layer.NewBubble(0, 0)
+		b.Z = z
+		for pos, c := range cells(f) {
+			if c != []rune(b.Asset.Sources[0][0])[0] {
+				b.Place(pos[0], pos[1])
+				break
+			}
+		}
+		return b
+	}
+	tests := []struct {
+		name   string
+		layers func() (front *layer.Layer, back *layer.Layer)
+	}{
+		{"near fish over far fish", func() (*layer.Layer, *layer.Layer) {
+			return fish("angelfish", 0), fish("pufferfish", 2)
+		}},
+		{"near fish over far bubble", func() (*layer.Layer, *layer.Layer) {
+			f := fish("angelfish", 0)
+			return f, bubble(f, 2)
+		}},
+		{"near bubble over far fish", func() (*layer.Layer, *layer.Layer) {
+			f := fish("angelfish", 2)
+			return bubble(f, 0), f
+		}},
+		{"bubble over fish at the same depth", func() (*layer.Layer, *layer.Layer) {
+			f := fish("angelfish", 1)
+			return bubble(f, 1), f
+		}},
+	}
+	for _, tt := range tests {
+		r := tank(t, 120, 40, 0, false)
+		r.swarm, r.bubbles = []*layer.Layer{}, []*layer.Layer{}
+		front, back := tt.layers()
+		// the front layer comes first, so it only ends up in front if the
+		// layers are sorted
+		for _, l := range []*layer.Layer{front, back} {
+			if l.Asset.Group == "bubble" {
+				r.bubbles = append(r.bubbles, l)
+			} else {
+				r.swarm = append(r.swarm, l)
+			}
+		}
+		r.repaint()
+		behind := cells(back)
+		overlaps := 0
+		for pos, want := range cells(front) {
+			got, _, _, _ := r.Screen.GetContent(pos[0], pos[1])
+			if got != want {
+				t.Errorf("%s: %q at %v, want %q", tt.name, got, pos, want)
+			}
+			if c, ok := behind[pos]; ok && c != want {
+				overlaps++
+			}
+		}
+		if overlaps == 0 {
+			t.Errorf("%s: layers dont overlap", tt.name)
+		}
+	}
+}
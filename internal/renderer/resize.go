@@ -0,0 +1,117 @@
+package renderer
+
+import (
+	"math"
+
+	"github.com/lukasjoc/nemo/internal/current"
+	"github.com/lukasjoc/nemo/internal/event"
+	"github.com/lukasjoc/nemo/internal/layer"
+)
+
+// Resize adapts the tank to the new size of the screen without starting
+// over. Everything keeps its place relative to the size of the water, the
+// floor moves to the bottom and the population grows or shrinks with the
+// area of the water. It publishes a resize event if the size changed.
+func (r *Renderer) Resize() {
+	r.resize()
+	r.flush()
+	// NOTE: while stopped the last frame is of the old size, so draw the
+	// tank again as it is now
+	if r.Paused() {
+		r.repaint()
+	}
+	r.redraw()
+}
+
+func (r *Renderer) resize() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	screenW, h := r.Screen.Size()
+	if screenW == r.screenW && h == r.h {
+		return
+	}
+	r.emit(event.Resize{W: screenW, H: h})
+	w := screenW * max(r.WorldScale, 1)
+	oldW, oldH := r.w, r.h
+	r.w, r.h, r.screenW = w, h, screenW
+	r.viewX = rescale(r.viewX, oldW, w)
+	r.viewX = min(max(r.viewX, 0), w-screenW)
+	if r.swarm == nil {
+		return
+	}
+	water, oldWater := h-layer.FloorHeight, oldH-layer.FloorHeight
+	for _, layers := range [][]*layer.Layer{r.swarm, r.glowers, r.bubbles, r.food} {
+		for _, l := range layers {
+			if l == nil {
+				continue
+			}
+			y := l.Y * water / max(oldWater, 1)
+			y = min(max(y, min(l.Y, 0)), water-l.Asset.Height)
+			l.Place(rescale(l.X, oldW, w), y)
+		}
+	}
+	for _, l := range r.walkers {
+		l.Place(rescale(l.X, oldW, w), l.Y+h-oldH)
+	}
+	r.floor.ResizeFloor(w, h)
+	if r.particles != nil {
+		r.particles = resized(r.particles, w*h/120, current.Particle{X: -1})
+	}
+
+	r.SwarmSize = int(math.Round(r.density * float64(r.area())))
+	// NOTE: the lifecycle keeps the population on its own
+	if !r.Lifecycle {
+		for len(r.swarm) < r.SwarmSize {
+			r.swarm = append(r.swarm, r.spawn(r.newRandFish(w, water)))
+		}
+		r.swarm = r.trim(r.swarm, r.SwarmSize)
+	}
+	for len(r.walkers) < r.walkerCount() {
+		r.walkers = append(r.walkers, r.spawn(layer.NewRandWalker(w, h)))
+	}
+	r.walkers = r.trim(r.walkers, r.walkerCount())
+	r.glowers = resized(r.trim(r.glowers, r.walkerCount()), r.walkerCount(), nil)
+}
+
+// area is the amount of cells of water.
+func (r *Renderer) area() int { return max(r.w*(r.h-layer.FloorHeight), 1) }
+
+// keepDensity remembers how many fish there are for the area of the water,
+// so that resizing keeps it. It is called whenever the swarm size is set.
+func (r *Renderer) keepDensity() {
+	r.density = float64(r.SwarmSize) / float64(r.area())
+}
+
+// rescale moves an x position on the screen to the same relative position
+// on a screen of the new width. Positions off the screen keep their
+// distance to the edge.
+func rescale(x int, from int, to int) int {
+	switch {
+	case x < 0:
+		return x
+	case x >= from:
+		return x + to - from
+	}
+	return x * to / max(from, 1)
+}
+
+// trim takes the layers beyond n out of the tank.
+func (r *Renderer) trim(layers []*layer.Layer, n int) []*layer.Layer {
+	if len(layers) <= n {
+		return layers
+	}
+	for _, l := range layers[n:] {
+		if l != nil {
+			r.despawn(l)
+		}
+	}
+	return layers[:n]
+}
+
+// resized cuts s to length n or fills it up with v.
+func resized[T any](s []T, n int, v T) []T {
+	for len(s) < n {
+		s = append(s, v)
+	}
+	return s[:n]
+}
@@ -0,0 +1,82 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/lukasjoc/nemo/internal/event"
+	"github.com/lukasjoc/nemo/internal/layer"
+)
+
+func TestRescale(t *testing.T) {
+	tests := []struct {
+		name     string
+		x        int
+		from, to int
+		want     int
+	}{
+		{"on the screen", 40, 80, 160, 80},
+		{"shrinking", 40, 80, 40, 20},
+		{"left of the screen", -10, 80, 160, -10},
+		{"right of the screen", 90, 80, 160, 170},
+		{"right of a shrinking screen", 90, 80, 40, 50},
+	}
+	for _, tt := range tests {
+		if got := rescale(tt.x, tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: rescale(%d, %d, %d) = %d, want %d", tt.name, tt.x, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		// where the fish at 40,10 ends up, unless it wouldnt fit into the
+		// water, and how many fish there are
+		x, y  int
+		swarm int
+	}{
+		{"growing", 160, 46, 80, 20, 40},
+		{"shrinking", 40, 13, 20, 5, 3},
+		{"flat", 80, 8, 40, 2, 3},
+		{"only wider", 160, 24, 80, 10, 20},
+		{"the same size", 80, 24, 40, 10, 10},
+	}
+	for _, tt := range tests {
+		r := tank(t, 80, 24, 10, false)
+		resized := []event.Resize{}
+		event.Subscribe(r.Events, func(e event.Resize) { resized = append(resized, e) })
+		l := r.swarm[0]
+		l.Place(40, 10)
+		r.Screen.(tcell.SimulationScreen).SetSize(tt.w, tt.h)
+		r.Resize()
+		y := min(tt.y, tt.h-layer.FloorHeight-l.Asset.Height)
+		if l.X != tt.x || l.Y != y {
+			t.Errorf("%s: fish at %d,%d, want %d,%d", tt.name, l.X, l.Y, tt.x, y)
+		}
+		if len(r.swarm) != tt.swarm || r.SwarmSize != tt.swarm {
+			t.Errorf("%s: %d fish of %d, want %d", tt.name, len(r.swarm), r.SwarmSize, tt.swarm)
+		}
+		if r.floor.Y != tt.h-layer.FloorHeight || r.floor.Asset.Width != tt.w {
+			t.Errorf("%s: floor at y=%d is %d wide, want y=%d and %d wide", tt.name, r.floor.Y, r.floor.Asset.Width, tt.h-layer.FloorHeight, tt.w)
+		}
+		for _, w := range r.walkers {
+			if w.Y+w.Asset.Height != r.floor.Y {
+				t.Errorf("%s: walker at y=%d isnt on the floor", tt.name, w.Y)
+			}
+		}
+		want := []event.Resize{{W: tt.w, H: tt.h}}
+		if tt.w == 80 && tt.h == 24 {
+			want = []event.Resize{}
+		}
+		if len(resized) != len(want) || len(want) > 0 && resized[0] != want[0] {
+			t.Errorf("%s: resize events = %v, want %v", tt.name, resized, want)
+		}
+		// NOTE: the renderer is stopped, so the tank is drawn again at the
+		// new size right away
+		if c, _, _, _ := r.Screen.GetContent(0, tt.h-1); c == ' ' {
+			t.Errorf("%s: the floor isnt drawn at the bottom", tt.name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package renderer
+
+import (
+	"time"
+
+	"github.com/lukasjoc/nemo/internal/event"
+	"github.com/lukasjoc/nemo/internal/layer"
+	"github.com/lukasjoc/nemo/internal/text"
+)
+
+// The amount of ticks the fish are in a frenzy after a countdown ran out.
+const alarmTicks = 60
+
+// ToggleTimer switches the timer to its next mode, from the clock to the
+// stopwatch to the countdown and back to the banner.
+func (r *Renderer) ToggleTimer() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Timer.Next(time.Now())
+}
+
+// updateTimer picks the lines of the banner and sounds the alarm once the
+// countdown ran out.
+func (r *Renderer) updateTimer(ts time.Time) {
+	if r.Timer.Update(ts) {
+		r.alarm = alarmTicks
+		r.emit(event.Alarm{})
+	}
+	t := r.Timer.Text(ts)
+	switch {
+	case t == "":
+		r.banner = r.Banner
+	case r.Font != nil:
+		r.banner = r.Font.Render(t)
+	default:
+		r.banner = text.Lines(t)
+	}
+}
+
+// frenzy lets the fish flee from the banner every few ticks while the
+// alarm is on.
+func (r *Renderer) frenzy() {
+	if r.alarm <= 0 {
+		return
+	}
+	r.alarm--
+	if r.alarm%10 != 0 {
+		return
+	}
+	name := r.nameBounds()
+	x, y := name.X+r.viewX+name.W/2, name.Y+name.H/2
+	for _, l := range layer.Visible(r.swarm) {
+		l.Scatter(x, y, r.h-layer.FloorHeight)
+	}
+}
@@ -0,0 +1,173 @@
+package script
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/assets"
+	"github.com/lukasjoc/nemo/internal/layer"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// The file extension of behavior scripts. A script named after a species,
+// like `sardine.star`, drives the behavior of every creature of it.
+const Ext = assets.ScriptExt
+
+// The amount of steps a script may take per tick, so that a broken script
+// cant hang the renderer.
+const maxSteps = 100000
+
+// A Script is a behavior written in Starlark. It has to define a function
+// `steer(me, neighbors)` that is called every tick with the state of the
+// creature and the creatures around it. It returns None to keep going or a
+// dict with the optional keys `dx` and `dy` to steer by, `turn` to turn
+// around and `rest` to skip the next movement.
+type Script struct {
+	Name  string
+	steer starlark.Callable
+}
+
+// Load loads all the scripts in a directory by the species they are named
+// after. A directory that doesnt exist has no scripts.
+func Load(dir string) (map[string]*Script, error) {
+	scripts := map[string]*Script{}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return scripts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != Ext {
+			continue
+		}
+		s, err := LoadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		scripts[s.Name] = s
+	}
+	return scripts, nil
+}
+
+// LoadAssets loads the scripts of all the species that have one, see
+// `assets.LoadDir`.
+func LoadAssets() (map[string]*Script, error) {
+	scripts := map[string]*Script{}
+	for _, group := range assets.Groups() {
+		for _, a := range assets.InGroup(group) {
+			if a.Script == "" {
+				continue
+			}
+			s, err := LoadFile(a.Script)
+			if err != nil {
+				return nil, err
+			}
+			scripts[a.Species] = s
+		}
+	}
+	return scripts, nil
+}
+
+func LoadFile(path string) (*Script, error) {
+	name := strings.TrimSuffix(filepath.Base(path), Ext)
+	thread := &starlark.Thread{Name: name}
+	thread.SetMaxExecutionSteps(maxSteps)
+	globals, err := starlark.ExecFile(thread, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	steer, ok := globals["steer"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s: no function `steer(me, neighbors)` defined", path)
+	}
+	return &Script{Name: name, steer: steer}, nil
+}
+
+// Think creates the behavior of a creature that is driven by the script.
+// The neighbors are looked up every tick. If the script fails the creature
+// goes back to the behavior it had before.
+func (s *Script) Think(l *layer.Layer, neighbors func(l *layer.Layer) []*layer.Layer) func(l *layer.Layer, w int, h int) {
+	fallback := l.Think
+	return func(l *layer.Layer, w int, h int) {
+		if err := s.run(l, neighbors(l), w, h); err != nil {
+			internal.Logln("SCRIPT %s failed: %v", s.Name, err)
+			l.Think = fallback
+		}
+	}
+}
+
+func (s *Script) run(l *layer.Layer, neighbors []*layer.Layer, w int, h int) error {
+	others := make([]starlark.Value, len(neighbors))
+	for i, n := range neighbors {
+		others[i] = creature(state(n))
+	}
+	// the creature itself also knows the size of the water
+	fields := state(l)
+	fields["w"] = starlark.MakeInt(w)
+	fields["h"] = starlark.MakeInt(h)
+	me := creature(fields)
+	thread := &starlark.Thread{Name: s.Name}
+	thread.SetMaxExecutionSteps(maxSteps)
+	res, err := starlark.Call(thread, s.steer, starlark.Tuple{me, starlark.NewList(others)}, nil)
+	if err != nil {
+		return err
+	}
+	if res == starlark.None {
+		return nil
+	}
+	steering, ok := res.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("steer returned %s, want a dict or None", res.Type())
+	}
+	dx, err := intField(steering, "dx")
+	if err != nil {
+		return err
+	}
+	dy, err := intField(steering, "dy")
+	if err != nil {
+		return err
+	}
+	l.Steer(dx, dy, h)
+	if v, ok, _ := steering.Get(starlark.String("turn")); ok && bool(v.Truth()) {
+		l.Turn()
+	}
+	if v, ok, _ := steering.Get(starlark.String("rest")); ok && bool(v.Truth()) {
+		l.Rest()
+	}
+	return nil
+}
+
+func creature(fields starlark.StringDict) starlark.Value {
+	return starlarkstruct.FromStringDict(starlark.String("creature"), fields)
+}
+
+func state(l *layer.Layer) starlark.StringDict {
+	return starlark.StringDict{
+		"x":       starlark.MakeInt(l.X),
+		"y":       starlark.MakeInt(l.Y),
+		"z":       starlark.MakeInt(l.Z),
+		"velo":    starlark.MakeInt(l.Velo),
+		"width":   starlark.MakeInt(l.Asset.Width),
+		"height":  starlark.MakeInt(l.Asset.Height),
+		"species": starlark.String(l.Species()),
+		"turning": starlark.Bool(l.Turning()),
+	}
+}
+
+func intField(d *starlark.Dict, key string) (int, error) {
+	v, ok, _ := d.Get(starlark.String(key))
+	if !ok {
+		return 0, nil
+	}
+	var n int
+	if err := starlark.AsInt(v, &n); err != nil {
+		return 0, fmt.Errorf("steer returned %s for `%s`: %v", v.Type(), key, err)
+	}
+	return n, nil
+}
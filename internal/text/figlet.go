@@ -0,0 +1,151 @@
+package text
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NOTE: the fonts are taken from the FIGlet distribution, see the comments
+// at the top of every font for its authors.
+//
+//go:embed fonts/*.flf
+var fonts embed.FS
+
+// The font banners are rendered in by default.
+const DefaultFont = "smslant"
+
+// A Font is a FIGlet font, which draws every character with a few lines of
+// smaller characters.
+type Font struct {
+	height    int
+	hardblank rune
+	chars     map[rune][]string
+}
+
+// ParseFont parses a FIGlet font in the `flf2a` format. Only the ASCII
+// characters of the font are used.
+func ParseFont(r io.Reader) (*Font, error) {
+	s := bufio.NewScanner(r)
+	if !s.Scan() {
+		return nil, fmt.Errorf("font is empty")
+	}
+	header := strings.Fields(s.Text())
+	if len(header) < 6 || !strings.HasPrefix(header[0], "flf2a") || len(header[0]) < 6 {
+		return nil, fmt.Errorf("not a FIGlet font")
+	}
+	f := Font{hardblank: []rune(header[0])[5], chars: map[rune][]string{}}
+	height, err := strconv.Atoi(header[1])
+	if err != nil || height <= 0 {
+		return nil, fmt.Errorf("invalid height `%s`", header[1])
+	}
+	f.height = height
+	comments, err := strconv.Atoi(header[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount of comment lines `%s`", header[5])
+	}
+	for i := 0; i < comments; i++ {
+		s.Scan()
+	}
+	for ch := ' '; ch <= '~'; ch++ {
+		lines := make([]string, f.height)
+		for i := range lines {
+			if !s.Scan() {
+				return nil, fmt.Errorf("character `%c` is incomplete", ch)
+			}
+			// every line ends with one or two end marks
+			line := s.Text()
+			if line == "" {
+				return nil, fmt.Errorf("character `%c` is incomplete", ch)
+			}
+			lines[i] = strings.TrimRight(line, line[len(line)-1:])
+		}
+		f.chars[ch] = lines
+	}
+	return &f, s.Err()
+}
+
+// LoadFont loads one of the built in fonts by its name, or a font file by
+// its path. An empty name is the default font.
+func LoadFont(name string) (*Font, error) {
+	if name == "" {
+		name = DefaultFont
+	}
+	file, err := fonts.Open("fonts/" + name + ".flf")
+	if err != nil {
+		if file, err = os.Open(name); err != nil {
+			return nil, fmt.Errorf("font `%s` doesnt exist", name)
+		}
+	}
+	defer file.Close()
+	f, err := ParseFont(file)
+	if err != nil {
+		return nil, fmt.Errorf("font `%s`: %v", name, err)
+	}
+	return f, nil
+}
+
+// Render draws the text in the font. The characters are moved together
+// until they touch, as FIGlet does it by default.
+func (f *Font) Render(s string) []string {
+	out := []string{}
+	for _, line := range Lines(s) {
+		rows := make([][]rune, f.height)
+		for _, ch := range line {
+			glyph, ok := f.chars[ch]
+			if !ok {
+				glyph = f.chars['?']
+			}
+			rows = f.kern(rows, glyph)
+		}
+		for _, row := range rows {
+			out = append(out, strings.ReplaceAll(string(row), string(f.hardblank), " "))
+		}
+	}
+	return out
+}
+
+// kern appends a glyph to the rows as close as it goes without overlapping.
+func (f *Font) kern(rows [][]rune, glyph []string) [][]rune {
+	shift := -1
+	for i, row := range rows {
+		g := []rune(glyph[i])
+		gap := min(trailing(row)+leading(g), len(row), len(g))
+		if shift < 0 || gap < shift {
+			shift = gap
+		}
+	}
+	for i, row := range rows {
+		start := len(row) - shift
+		for j, ch := range []rune(glyph[i]) {
+			switch {
+			case start+j >= len(row):
+				row = append(row, ch)
+			case ch != ' ':
+				row[start+j] = ch
+			}
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+func leading(row []rune) int {
+	n := 0
+	for n < len(row) && row[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+func trailing(row []rune) int {
+	n := 0
+	for n < len(row) && row[len(row)-1-n] == ' ' {
+		n++
+	}
+	return n
+}
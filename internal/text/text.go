@@ -0,0 +1,89 @@
+package text
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// Align is where the lines are placed within the width of a box.
+type Align int
+
+const (
+	Left Align = iota
+	Center
+	Right
+)
+
+// Lines splits a text into its lines.
+func Lines(s string) []string { return strings.Split(s, "\n") }
+
+// Width is the length of the longest line.
+func Width(lines []string) int {
+	w := 0
+	for _, line := range lines {
+		w = max(w, len([]rune(line)))
+	}
+	return w
+}
+
+// Wrap breaks the lines at spaces, so that they fit into the width. Words
+// longer than the width are broken where they dont fit anymore.
+func Wrap(lines []string, w int) []string {
+	if w <= 0 {
+		return lines
+	}
+	wrapped := []string{}
+	for _, line := range lines {
+		current := []rune{}
+		for _, word := range strings.Split(line, " ") {
+			runes := []rune(word)
+			if len(current) > 0 && len(current)+1+len(runes) > w {
+				wrapped = append(wrapped, string(current))
+				current = current[:0]
+			} else if len(current) > 0 {
+				current = append(current, ' ')
+			}
+			for len(runes) > w {
+				wrapped = append(wrapped, string(runes[:w]))
+				runes = runes[w:]
+			}
+			current = append(current, runes...)
+		}
+		wrapped = append(wrapped, string(current))
+	}
+	return wrapped
+}
+
+// Fill fills the box at x, y with the size w, h with spaces.
+func Fill(sc tcell.Screen, x int, y int, w int, h int, style tcell.Style) {
+	for i := 0; i < h; i++ {
+		for j := 0; j < w; j++ {
+			sc.SetContent(x+j, y+i, ' ', nil, style)
+		}
+	}
+}
+
+// Draw draws the lines into the box at x, y with the size w, h. Whatever
+// doesnt fit into the box is cut off.
+func Draw(sc tcell.Screen, x int, y int, w int, h int, lines []string, align Align, style tcell.Style) {
+	for i, line := range lines {
+		if i >= h {
+			return
+		}
+		runes := []rune(line)
+		dx := 0
+		switch align {
+		case Center:
+			dx = (w - len(runes)) / 2
+		case Right:
+			dx = w - len(runes)
+		}
+		for j, ch := range runes {
+			if dx+j < 0 || dx+j >= w {
+				continue
+			}
+			sc.SetContent(x+dx+j, y+i, ch, nil, style)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package text
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// font makes a font of height 2 in which every character is drawn by
+// itself, except for the ones given.
+func font(glyphs map[rune][2]string) string {
+	var sb strings.Builder
+	sb.WriteString("flf2a$ 2 2 5 0 1\ncomment\n")
+	for ch := ' '; ch <= '~'; ch++ {
+		g, ok := glyphs[ch]
+		if !ok {
+			g = [2]string{string(ch), string(ch)}
+		}
+		fmt.Fprintf(&sb, "%s@\n%s@@\n", g[0], g[1])
+	}
+	return sb.String()
+}
+
+func TestParseFont(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  bool
+	}{
+		{"font", font(nil), false},
+		{"empty", "", true},
+		{"not a font", "hello\n", true},
+		{"invalid height", "flf2a$ x 2 5 0 0\n", true},
+		{"invalid comments", "flf2a$ 2 2 5 0 x\n", true},
+		{"incomplete", "flf2a$ 2 2 5 0 0\n @\n @@\n", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseFont(strings.NewReader(tt.data))
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error = %v, want error %t", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	f, err := ParseFont(strings.NewReader(font(map[rune][2]string{
+		' ': {"$", "$"},
+		'L': {"L ", "LL"},
+		'k': {" k", " k"},
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"ab", []string{"ab", "ab"}},
+		{"a b", []string{"a b", "a b"}},
+		{"a\nb", []string{"a", "a", "b", "b"}},
+		// moved together until the characters touch
+		{"Lk", []string{"L k", "LLk"}},
+		{"ak", []string{"ak", "ak"}},
+		{"é", []string{"?", "?"}},
+	}
+	for _, tt := range tests {
+		if got := f.Render(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Render(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFont(t *testing.T) {
+	for _, name := range []string{"", "slant", "small", "smslant", "standard"} {
+		f, err := LoadFont(name)
+		if err != nil {
+			t.Errorf("LoadFont(%q) error = %v", name, err)
+			continue
+		}
+		if lines := f.Render("nemo"); len(lines) == 0 || Width(lines) == 0 {
+			t.Errorf("LoadFont(%q) renders nothing", name)
+		}
+	}
+	if _, err := LoadFont("nope"); err == nil {
+		t.Errorf("LoadFont(%q) doesnt fail", "nope")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		lines []string
+		w     int
+		want  []string
+	}{
+		{[]string{"a b c"}, 10, []string{"a b c"}},
+		{[]string{"aa bb cc"}, 5, []string{"aa bb", "cc"}},
+		{[]string{"aa bb", "cc"}, 2, []string{"aa", "bb", "cc"}},
+		{[]string{"abcdefg"}, 3, []string{"abc", "def", "g"}},
+		{[]string{"a abcdef"}, 3, []string{"a", "abc", "def"}},
+		{[]string{"äöü äöü"}, 3, []string{"äöü", "äöü"}},
+		{[]string{""}, 3, []string{""}},
+		{[]string{"a b"}, 0, []string{"a b"}},
+	}
+	for _, tt := range tests {
+		if got := Wrap(tt.lines, tt.w); !slices.Equal(got, tt.want) {
+			t.Errorf("Wrap(%q, %d) = %q, want %q", tt.lines, tt.w, got, tt.want)
+		}
+	}
+}
@@ -2,86 +2,321 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gdamore/tcell"
 	"github.com/lukasjoc/nemo/internal"
+	"github.com/lukasjoc/nemo/internal/assets"
+	"github.com/lukasjoc/nemo/internal/config"
+	"github.com/lukasjoc/nemo/internal/event"
+	"github.com/lukasjoc/nemo/internal/keymap"
+	"github.com/lukasjoc/nemo/internal/record"
 	"github.com/lukasjoc/nemo/internal/renderer"
+	"github.com/lukasjoc/nemo/internal/script"
+	"github.com/lukasjoc/nemo/internal/text"
 )
 
+// The amount of fish that swim in together when adding a school.
+const schoolSize = 5
+
 func main() {
+	cmd, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "run":
+		run(parseOptions(cmd, args, false))
+	case "record":
+		run(parseOptions(cmd, args, true))
+	case "replay":
+		replay(args)
+	case "assets":
+		listAssets(args)
+	case "idle":
+		idleShell(args)
+	case "version":
+		fmt.Println("nemo", version)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		fail("Unknown command `%s`", cmd)
+	}
+}
+
+func run(o options) {
+	internal.DebugEnabled = internal.DebugEnabled || o.debug
+	internal.ProfEnabled = internal.ProfEnabled || o.prof
 	internal.DebugStart()
 
+	if o.seed != 0 {
+		internal.Seed(o.seed)
+	}
+	for _, dir := range o.assets {
+		if err := assets.LoadDir(dir); err != nil {
+			fail("Couldnt load assets: %v", err)
+		}
+	}
+	file, err := config.Load(o.config)
+	if err != nil {
+		fail("Couldnt load config: %v", err)
+	}
+	c, keys, err := settings(file, o)
+	if err != nil {
+		fail("Couldnt load config: %v", err)
+	}
+	scale := o.world
+	scripts, err := script.LoadAssets()
+	if err != nil {
+		fail("Couldnt load scripts: %v", err)
+	}
+	// the scripts of the extra directory win over the ones of the assets
+	if o.scripts != "" {
+		more, err := script.Load(o.scripts)
+		if err != nil {
+			fail("Couldnt load scripts: %v", err)
+		}
+		maps.Copy(scripts, more)
+	}
+	var out *os.File
+	if o.record != "" {
+		if out, err = os.Create(o.record); err != nil {
+			fail("Couldnt create recording: %v", err)
+		}
+		defer out.Close()
+	}
+
 	// TODO: should the renderer create the screen automatically?
 	sc, err := tcell.NewScreen()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Couldnt create screen: %v\n", err)
-		os.Exit(1)
+		fail("Couldnt create screen: %v", err)
 	}
 
 	if err := sc.Init(); err != nil {
-		fmt.Fprintf(os.Stderr, "Couldnt init tcell: %v\n", err)
-		os.Exit(1)
+		fail("Couldnt init tcell: %v", err)
 	}
 	sc.SetStyle(tcell.StyleDefault)
+	sc.EnableMouse()
+	sc.HideCursor()
 	sc.Clear()
 
-	r := renderer.New(sc, 18, renderer.DefaultTickDelay)
+	// every screen of the tank has its own fish
+	r := renderer.New(sc, *c.Swarm*scale, time.Duration(*c.Tick))
+	r.WorldScale = scale
+	r.Scripts = scripts
+	apply(r, nil, c, scale)
+	if internal.DebugEnabled {
+		r.Events.SubscribeAll(func(e event.Event) {
+			internal.Logln("EVENT %T %+v", e, e)
+		})
+	}
+	if out != nil {
+		w, h := sc.Size()
+		rec, err := record.New(out, w, h, time.Now())
+		if err != nil {
+			sc.Fini()
+			fail("Couldnt start recording: %v", err)
+		}
+		event.Subscribe(r.Events, func(e event.Frame) {
+			if err := rec.Frame(e.Cells, e.Time); err != nil {
+				internal.Logln("RECORD failed: %v", err)
+			}
+		})
+	}
+	// the terminal is restored only once, no matter if it is quit by a
+	// signal, a key or a panic
+	var finish sync.Once
 	quit := func() {
+		finish.Do(func() {
+			r.Stop()
+			r.Destroy()
+			sc.Fini()
+		})
+	}
+	defer func() {
 		p := recover()
-		r.Stop()
-		r.Destroy()
-		sc.Fini()
+		quit()
 		if p != nil {
 			panic(p)
 		}
-	}
-	defer quit()
+	}()
 	defer internal.DebugEnd()
 
 	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	// quit like on a key, so the deferred calls run
 	go func() {
 		<-sigs
-		quit()
-		os.Exit(1)
+		sc.PostEventWait(tcell.NewEventInterrupt(nil))
 	}()
 
 	r.Reset()
 	r.Start()
+	if o.duration > 0 {
+		time.AfterFunc(o.duration, func() { sc.PostEvent(tcell.NewEventInterrupt(nil)) })
+	}
+	if o.config != "" {
+		config.Watch(o.config, func(file *config.Config, err error) {
+			sc.PostEvent(tcell.NewEventInterrupt(reload{file, err}))
+		})
+	}
 
 	initW, initH := sc.Size()
+	// where the left mouse button was pressed, or -1 if it isnt
+	pressX, pressY := -1, -1
 	for {
 		ev := sc.PollEvent()
 		evW, evH := sc.Size()
+		switch ev.(type) {
+		case *tcell.EventKey, *tcell.EventMouse:
+			// the screensaver ends on any input, even a mouse move
+			if o.screensaver {
+				return
+			}
+		}
 		switch ev := ev.(type) {
+		case *tcell.EventInterrupt:
+			next, ok := ev.Data().(reload)
+			if !ok {
+				return
+			}
+			nextC, nextKeys, err := next.settings(o)
+			if err != nil {
+				// keep the settings until the file is fixed
+				internal.Logln("CONFIG reload failed: %v", err)
+				r.SetNotice(text.Lines("Couldnt reload config:\n" + err.Error()))
+				continue
+			}
+			apply(r, c, nextC, scale)
+			c, keys = nextC, nextKeys
 		case *tcell.EventResize:
 			nextW, nextH := ev.Size()
 			if nextW == initW && nextH == initH {
 				continue
 			}
-			r.Restart()
+			initW, initH = nextW, nextH
+			r.Resize()
 		case *tcell.EventKey:
-			if ev.Key() == tcell.KeyEscape ||
-				ev.Key() == tcell.KeyCtrlC {
+			r.Events.Publish(event.Key{Event: ev})
+			action, ok := keys.Lookup(ev)
+			if !ok {
+				continue
+			}
+			internal.Logln("KEY EVENT %s %s t:%d, w:%d, h:%d", ev.Name(), action, ev.When().Unix(), evW, evH)
+			switch action {
+			case keymap.Quit:
 				return
+			case keymap.Pause:
+				select {
+				case <-r.Stopped:
+					r.Start()
+				default:
+					r.Stop()
+				}
+			case keymap.Step:
+				r.Step()
+			case keymap.Restart:
+				r.Restart()
+			case keymap.SpeedUp:
+				r.SpeedUp()
+			case keymap.SpeedDown:
+				r.SlowDown()
+			case keymap.AddFish:
+				r.AddFish()
+			case keymap.RemoveFish:
+				r.RemoveFish()
+			case keymap.AddSchool:
+				species := internal.Choose(assets.Species("fish")...)
+				if err := r.AddSpecies(species, schoolSize); err != nil {
+					internal.Logln("ADD SCHOOL failed: %v", err)
+				}
+			case keymap.RemoveSpecies:
+				if species := r.LastSpecies(); species != "" {
+					r.RemoveSpecies(species)
+				}
+			case keymap.Stats:
+				r.ToggleStats()
+			case keymap.Screenshot:
+				screenshot(r)
+			case keymap.Help:
+				r.ToggleHelp(keys.Help())
+			case keymap.Inspect:
+				r.ToggleInspector()
+			case keymap.Next:
+				r.SelectNext()
+			case keymap.Freeze:
+				r.FreezeSelected()
+			case keymap.Remove:
+				r.RemoveSelected()
+			case keymap.Timer:
+				r.ToggleTimer()
+			case keymap.Follow:
+				r.Follow()
+			case keymap.Minimap:
+				r.ToggleMinimap()
+			case keymap.Up:
+				r.MoveCursor(0, -1)
+			case keymap.Down:
+				r.MoveCursor(0, 1)
+			case keymap.Left:
+				// the arrows move the cursor while inspecting and scroll
+				// through the tank otherwise
+				if r.Inspecting() {
+					r.MoveCursor(-2, 0)
+				} else {
+					r.Scroll(-1)
+				}
+			case keymap.Right:
+				if r.Inspecting() {
+					r.MoveCursor(2, 0)
+				} else {
+					r.Scroll(1)
+				}
 			}
-			if ev.Key() == tcell.KeyRune {
-				switch ev.Rune() {
-				case 'p':
-					internal.Logln("KEY EVENT %s t:%d, w:%d, h:%d", ev.Name(), ev.When().Unix(), evW, evH)
-					select {
-					case <-r.Stopped:
-						r.Start()
-					default:
-						r.Stop()
-					}
-				case 'r':
-					r.Restart()
+		case *tcell.EventMouse:
+			x, y := ev.Position()
+			switch {
+			case ev.Buttons()&tcell.Button1 != 0:
+				if pressX < 0 {
+					pressX, pressY = x, y
 				}
+			case ev.Buttons()&(tcell.Button2|tcell.Button3) != 0:
+				r.Blow(x, y)
+			case pressX >= 0:
+				// a short drag is still a click
+				switch {
+				case internal.Abs(x-pressX)+internal.Abs(y-pressY) > 2:
+					r.Drag(pressX, pressY, x, y)
+				case r.Inspecting():
+					r.Select(x, y)
+				default:
+					r.Click(x, y)
+				}
+				pressX, pressY = -1, -1
+			default:
+				r.Hover(x, y)
 			}
 		}
 	}
 }
+
+// screenshot writes the screen to a text file in the working directory.
+func screenshot(r *renderer.Renderer) {
+	name := fmt.Sprintf("nemo-%d.txt", time.Now().Unix())
+	f, err := os.Create(name)
+	if err != nil {
+		internal.Logln("SCREENSHOT failed: %v", err)
+		return
+	}
+	defer f.Close()
+	if err := r.Screenshot(f); err != nil {
+		internal.Logln("SCREENSHOT failed: %v", err)
+	}
+}